@@ -0,0 +1,255 @@
+package lgr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// OverflowPolicy decides what the async writer does when its queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest drops the entry being written
+	OverflowDropNewest OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest queued entry to make room for the entry being written
+	OverflowDropOldest
+	// OverflowBlock blocks the caller until there is room in the queue
+	OverflowBlock
+)
+
+var overflowPolicyNames = [...]string{
+	OverflowDropNewest: "drop_newest",
+	OverflowDropOldest: "drop_oldest",
+	OverflowBlock:      "block",
+}
+
+func (p OverflowPolicy) String() string {
+	if p < OverflowDropNewest || p > OverflowBlock {
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+	return overflowPolicyNames[p]
+}
+
+// MarshalText marshals the policy to its name, e.g. "drop_newest"
+func (p OverflowPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText unmarshals a policy name, e.g. "drop_newest", as used in config files
+func (p *OverflowPolicy) UnmarshalText(text []byte) error {
+	for policy, name := range overflowPolicyNames {
+		if string(text) == name {
+			*p = OverflowPolicy(policy)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown overflow policy %q", text)
+}
+
+// asyncFlushSize is the size of buffered entries which triggers a write to the underlying sink
+const asyncFlushSize = 256 * 1024
+
+var _ zapcore.WriteSyncer = (*asyncWriter)(nil)
+
+// asyncWriter writes entries to the underlying sink in a background goroutine,
+// entries are queued in a bounded queue and buffered until the flush interval elapses.
+type asyncWriter struct {
+	ws      zapcore.WriteSyncer
+	errSink zapcore.WriteSyncer
+	policy  OverflowPolicy
+	dropped *uint64
+
+	queue   chan []byte
+	syncReq chan chan error
+	ticker  *time.Ticker
+
+	// stopped is guarded by mu, which stop holds until the background goroutine exits,
+	// entries are written directly to the underlying sink once stopped
+	mu       sync.RWMutex
+	stopped  bool
+	stopping chan struct{}
+	abandon  chan struct{}
+	done     chan struct{}
+
+	// only accessed by the background goroutine
+	buf      bytes.Buffer
+	buffered int
+}
+
+func newAsyncWriter(ws, errSink zapcore.WriteSyncer, bufferSize int, flushInterval time.Duration, policy OverflowPolicy, dropped *uint64) *asyncWriter {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+	w := &asyncWriter{
+		ws:       ws,
+		errSink:  errSink,
+		policy:   policy,
+		dropped:  dropped,
+		queue:    make(chan []byte, bufferSize),
+		syncReq:  make(chan chan error),
+		stopping: make(chan struct{}),
+		abandon:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if flushInterval > 0 {
+		w.ticker = time.NewTicker(flushInterval)
+	}
+	go w.run()
+	return w
+}
+
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.stopped {
+		return w.ws.Write(p)
+	}
+
+	// the encoder reuses the buffer once Write returns
+	entry := make([]byte, len(p))
+	copy(entry, p)
+
+	switch w.policy {
+	case OverflowBlock:
+		w.queue <- entry
+	case OverflowDropOldest:
+		for {
+			select {
+			case w.queue <- entry:
+				return len(p), nil
+			default:
+			}
+			select {
+			case <-w.queue:
+				atomic.AddUint64(w.dropped, 1)
+			default:
+			}
+		}
+	default:
+		select {
+		case w.queue <- entry:
+		default:
+			atomic.AddUint64(w.dropped, 1)
+		}
+	}
+	return len(p), nil
+}
+
+// Sync blocks until all the entries queued before the call are written to the underlying sink
+func (w *asyncWriter) Sync() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.stopped {
+		return w.ws.Sync()
+	}
+
+	ch := make(chan error)
+	w.syncReq <- ch
+	return <-ch
+}
+
+// stop writes all the queued entries and stops the background goroutine.
+// if ctx is done before the queued entries are written, the remaining entries are dropped and ctx.Err() is returned.
+// either way the background goroutine has exited when stop returns, the writes are blocked meanwhile to keep their order.
+func (w *asyncWriter) stop(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return nil
+	}
+	w.stopped = true
+
+	close(w.stopping)
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+	}
+	close(w.abandon)
+	<-w.done
+	return ctx.Err()
+}
+
+func (w *asyncWriter) run() {
+	var tick <-chan time.Time
+	if w.ticker != nil {
+		tick = w.ticker.C
+	}
+	defer close(w.done)
+	for {
+		select {
+		case entry := <-w.queue:
+			w.add(entry)
+			if w.ticker == nil || w.buf.Len() >= asyncFlushSize {
+				w.flush()
+			}
+		case <-tick:
+			w.flush()
+		case ch := <-w.syncReq:
+			w.drain()
+			w.flush()
+			ch <- w.ws.Sync()
+		case <-w.stopping:
+			if w.ticker != nil {
+				w.ticker.Stop()
+			}
+			w.finish()
+			return
+		}
+	}
+}
+
+// finish writes the queued entries until the queue is empty or stop abandons them
+func (w *asyncWriter) finish() {
+	for {
+		select {
+		case <-w.abandon:
+			atomic.AddUint64(w.dropped, uint64(w.buffered+len(w.queue)))
+			return
+		case entry := <-w.queue:
+			w.add(entry)
+			if w.buf.Len() >= asyncFlushSize {
+				w.flush()
+			}
+		default:
+			w.flush()
+			return
+		}
+	}
+}
+
+// drain moves all the queued entries to the buffer
+func (w *asyncWriter) drain() {
+	for {
+		select {
+		case entry := <-w.queue:
+			w.add(entry)
+		default:
+			return
+		}
+	}
+}
+
+func (w *asyncWriter) add(entry []byte) {
+	w.buf.Write(entry)
+	w.buffered++
+}
+
+func (w *asyncWriter) flush() {
+	if w.buf.Len() == 0 {
+		return
+	}
+	_, err := w.ws.Write(w.buf.Bytes())
+	w.buf.Reset()
+	w.buffered = 0
+	if err != nil {
+		fmt.Fprintf(w.errSink, "%v write error: %v\n", time.Now(), err)
+		_ = w.errSink.Sync()
+	}
+}
@@ -0,0 +1,130 @@
+package lgr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// lockedBuffer is a bytes.Buffer safe for the concurrent write of the async writer
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// blockingWriter blocks each write until unblocked
+type blockingWriter struct {
+	lockedBuffer
+	started chan struct{}
+	unblock chan struct{}
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{started: make(chan struct{}, 100), unblock: make(chan struct{})}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.started <- struct{}{}
+	<-w.unblock
+	return w.lockedBuffer.Write(p)
+}
+
+func TestAsyncSync(t *testing.T) {
+	buf := &lockedBuffer{}
+	log := NewLogger(WithCustomSink(buf), WithAsync(16, time.Hour, OverflowBlock), WithTimeKey(""), WithDisableCaller(true))
+	child := log.Named("child")
+	for i := 0; i < 5; i++ {
+		child.Info("async message", "i", i)
+	}
+	if err := child.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "async message"); n != 5 {
+		t.Errorf("expect all 5 entries written after Sync, got %d:\n%s", n, buf.String())
+	}
+}
+
+func TestAsyncOverflow(t *testing.T) {
+	tests := []struct {
+		policy OverflowPolicy
+		expect string
+	}{
+		{OverflowDropNewest, "msg-1 msg-2 msg-3"},
+		{OverflowDropOldest, "msg-1 msg-5 msg-6"},
+	}
+	for _, tt := range tests {
+		w := newBlockingWriter()
+		log := NewLogger(WithCustomSink(w), WithAsync(2, 0, tt.policy), WithEncoding("console"), WithTimeKey(""), WithDisableCaller(true), WithColorLevel(false))
+
+		log.Info("msg-1")
+		// wait for the background goroutine to block on the first entry
+		<-w.started
+		for _, msg := range []string{"msg-2", "msg-3", "msg-4", "msg-5", "msg-6"} {
+			log.Info(msg)
+		}
+		close(w.unblock)
+		if err := log.Sync(); err != nil {
+			t.Fatal(err)
+		}
+
+		got := strings.Join(strings.Fields(strings.ReplaceAll(w.String(), "info", "")), " ")
+		if got != tt.expect {
+			t.Errorf("policy %d: expect %q written, got %q", tt.policy, tt.expect, got)
+		}
+		if dropped := log.Stats().AsyncDropped; dropped != 3 {
+			t.Errorf("policy %d: expect 3 dropped entries, got %d", tt.policy, dropped)
+		}
+	}
+}
+
+func TestAsyncWriteDuringStop(t *testing.T) {
+	// the buffer is not locked, the race detector reports the writes from two goroutines
+	var buf bytes.Buffer
+	var dropped uint64
+	w := newAsyncWriter(zapcore.AddSync(&buf), zapcore.AddSync(io.Discard), 1024, time.Hour, OverflowBlock, &dropped)
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(w, "%d\n", i)
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 100; i < 200; i++ {
+			fmt.Fprintf(w, "%d\n", i)
+		}
+	}()
+	if err := w.stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 200 {
+		t.Fatalf("expect 200 entries, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if line != strconv.Itoa(i) {
+			t.Fatalf("expect the entries in order, got %q at %d", line, i)
+		}
+	}
+}
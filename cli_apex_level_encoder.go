@@ -32,6 +32,27 @@ var Strings = [...]string{
 	zapcore.FatalLevel + 1:  "⨯",
 }
 
+// TraceColor and TraceString are used for TraceLevel, which has no room in Colors and Strings
+// as they are indexed by level+1 and TraceLevel is -2.
+var (
+	TraceColor  = color.New(color.FgHiBlack)
+	TraceString = "·"
+)
+
+func levelColor(level zapcore.Level) *color.Color {
+	if level < zapcore.DebugLevel {
+		return TraceColor
+	}
+	return Colors[level+1]
+}
+
+func levelString(level zapcore.Level) string {
+	if level < zapcore.DebugLevel {
+		return TraceString
+	}
+	return Strings[level+1]
+}
+
 func forceEnableColor() {
 	var forceColored bool
 	switch force, ok := os.LookupEnv("CLICOLOR_FORCE"); {
@@ -42,6 +63,7 @@ func forceEnableColor() {
 	}
 
 	if forceColored {
+		TraceColor.EnableColor()
 		for _, v := range Colors {
 			if v != nil {
 				v.EnableColor()
@@ -52,7 +74,7 @@ func forceEnableColor() {
 
 func CliLevelEncoder(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
 	padding := 3
-	color := Colors[level+1]
-	lvlIcon := Strings[level+1]
+	color := levelColor(level)
+	lvlIcon := levelString(level)
 	enc.AppendString(color.Sprintf("%s", bold.Sprintf("%*s", padding+1, lvlIcon)))
 }
@@ -530,7 +530,7 @@ func (enc *cliEncoder) addElementSeparator() {
 }
 
 func addFields(enc zapcore.ObjectEncoder, level zapcore.Level, fields []zapcore.Field) {
-	color := Colors[level+1]
+	color := levelColor(level)
 
 	for i := range fields {
 		fields[i].Key = color.Sprintf("%s", fields[i].Key)
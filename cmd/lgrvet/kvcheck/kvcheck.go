@@ -0,0 +1,122 @@
+// Package kvcheck defines an analyzer which reports malformed key value pairs
+// passed to the lgr logging methods, i.e. a non-string key or a key without value.
+package kvcheck
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const (
+	lgrPath     = "github.com/ttys3/lgr"
+	zapcorePath = "go.uber.org/zap/zapcore"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "kvcheck",
+	Doc:      "report malformed key value pairs passed to the lgr logging methods",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// kvMethods maps the methods of lgr.LogImpl and lgr.Logger to the index of their first key value argument
+var kvMethods = map[string]int{
+	"Trace":    1,
+	"Debug":    1,
+	"Info":     1,
+	"Warn":     1,
+	"Error":    1,
+	"DPanic":   1,
+	"Panic":    1,
+	"Fatal":    1,
+	"DebugCtx": 2,
+	"InfoCtx":  2,
+	"WarnCtx":  2,
+	"ErrorCtx": 2,
+	"With":     0,
+}
+
+// kvFuncs maps the lgr functions to the index of their first key value argument
+var kvFuncs = map[string]int{
+	"WithInitialFields": 0,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		if call.Ellipsis.IsValid() {
+			// the pairs are not known statically
+			return
+		}
+		first, ok := kvStart(pass, call)
+		if !ok || first > len(call.Args) {
+			return
+		}
+		checkPairs(pass, call.Args[first:])
+	})
+	return nil, nil
+}
+
+// kvStart returns the index of the first key value argument if call is an lgr logging call
+func kvStart(pass *analysis.Pass, call *ast.CallExpr) (int, bool) {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != lgrPath {
+		return 0, false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		first, ok := kvFuncs[fn.Name()]
+		return first, ok
+	}
+
+	t := recv.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || (named.Obj().Name() != "LogImpl" && named.Obj().Name() != "Logger") {
+		return 0, false
+	}
+	first, ok := kvMethods[fn.Name()]
+	return first, ok
+}
+
+func checkPairs(pass *analysis.Pass, args []ast.Expr) {
+	for i := 0; i < len(args); {
+		t := pass.TypesInfo.TypeOf(args[i])
+		if t == nil {
+			return
+		}
+		if isField(t) {
+			i++
+			continue
+		}
+		// the keys are asserted with .(string) at runtime, so a named string type is a bad key too
+		if basic, ok := types.Unalias(t).(*types.Basic); !ok || (basic.Kind() != types.String && basic.Kind() != types.UntypedString) {
+			pass.Reportf(args[i].Pos(), "non-string key of type %s, it is logged as !BADKEY", t)
+			i++
+			continue
+		}
+		if i == len(args)-1 {
+			pass.Reportf(args[i].Pos(), "key without value, it is logged as !BADKEY")
+			return
+		}
+		i += 2
+	}
+}
+
+// isField reports whether t is zap.Field, which is logged as is
+func isField(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == zapcorePath && obj.Name() == "Field"
+}
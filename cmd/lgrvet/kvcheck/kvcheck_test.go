@@ -0,0 +1,13 @@
+package kvcheck_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/ttys3/lgr/cmd/lgrvet/kvcheck"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), kvcheck.Analyzer, "a")
+}
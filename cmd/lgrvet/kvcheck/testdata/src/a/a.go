@@ -0,0 +1,24 @@
+package a
+
+import (
+	"context"
+
+	"github.com/ttys3/lgr"
+)
+
+type userID string
+
+func calls(log *lgr.LogImpl, iface lgr.Logger, ctx context.Context, kv []interface{}) {
+	const key = "name"
+	log.Info("well formed", "uid", 7, lgr.String("name", "user001"), key, "user001")
+	log.Info("named string", userID("key"), "v") // want `non-string key of type a.userID` `key without value`
+	log.Info("dangling", "uid", 7, "name")       // want `key without value`
+	log.Info("non-string", 7, "uid")             // want `non-string key of type int` `key without value`
+	log.InfoCtx(ctx, "ctx", "uid")               // want `key without value`
+	log.With("uid").Info("with")                 // want `key without value`
+	iface.Info("interface", 42, "x")             // want `non-string key of type int` `key without value`
+	lgr.WithInitialFields("app")                 // want `key without value`
+
+	log.Info("unknown pairs", kv...)
+	log.Infof("uid %d", 7)
+}
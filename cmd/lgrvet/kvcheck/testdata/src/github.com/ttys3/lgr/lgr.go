@@ -0,0 +1,27 @@
+// Package lgr is a stub of the logging API checked by kvcheck
+package lgr
+
+import (
+	"context"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type Field = zapcore.Field
+
+func String(key, val string) Field { return Field{Key: key, String: val} }
+
+type Option func(*LogImpl)
+
+func WithInitialFields(kv ...interface{}) Option { return nil }
+
+type Logger interface {
+	Info(msg string, keysAndValues ...interface{})
+}
+
+type LogImpl struct{}
+
+func (l *LogImpl) Info(msg string, keysAndValues ...interface{})                         {}
+func (l *LogImpl) InfoCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {}
+func (l *LogImpl) Infof(template string, args ...interface{})                            {}
+func (l *LogImpl) With(keysAndValues ...interface{}) *LogImpl                            { return l }
@@ -0,0 +1,6 @@
+package zapcore
+
+type Field struct {
+	Key    string
+	String string
+}
@@ -0,0 +1,15 @@
+// lgrvet reports malformed key value pairs passed to the lgr logging methods.
+//
+//	go install github.com/ttys3/lgr/cmd/lgrvet@latest
+//	go vet -vettool=$(which lgrvet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/ttys3/lgr/cmd/lgrvet/kvcheck"
+)
+
+func main() {
+	singlechecker.Main(kvcheck.Analyzer)
+}
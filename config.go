@@ -0,0 +1,97 @@
+package lgr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configKeys maps the Config field names to their config file keys
+var configKeys = func() map[string]string {
+	t := reflect.TypeOf(Config{})
+	keys := make(map[string]string, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if key != "" && key != "-" {
+			keys[f.Name] = key
+		}
+	}
+	return keys
+}()
+
+// DefaultConfig returns the config used by NewLogger before the options are applied
+func DefaultConfig() Config {
+	return defaultCfg().Config
+}
+
+// LoadConfig reads a YAML or JSON config file, chosen by the file extension.
+// the keys not in the file keep their default values, an unknown key is an error.
+// the invalid fields are reported by a *ConfigError, each FieldError points to the offending key.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return DefaultConfig(), err
+	}
+	return parseConfig(path, data)
+}
+
+// parseConfig parses the content of the config file at path, see LoadConfig
+func parseConfig(path string, data []byte) (Config, error) {
+	cfg := DefaultConfig()
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+		return cfg, fmt.Errorf("unsupported config file %s, must be .yaml, .yml or .json", path)
+	}
+
+	// JSON is a subset of YAML, the yaml decoder also parses durations like "1s" in JSON files
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := cfg.validate(); err != nil {
+		var cfgErr *ConfigError
+		if errors.As(err, &cfgErr) {
+			lines := keyLines(data)
+			for _, fe := range cfgErr.Errors {
+				fe.Line = lines[fe.Key]
+			}
+		}
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// keyLines returns the lines of the top level keys of the config file
+func keyLines(data []byte) map[string]int {
+	lines := map[string]int{}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return lines
+	}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		lines[root.Content[i].Value] = root.Content[i].Line
+	}
+	return lines
+}
+
+// NewFromConfig creates a logger from cfg, e.g. loaded by LoadConfig, with the options applied,
+// the errors are reported like NewLoggerE does.
+func NewFromConfig(cfg Config, options ...Option) (*LogImpl, error) {
+	l := &LogImpl{Config: cfg}
+	for _, option := range options {
+		option(l)
+	}
+	return l.build()
+}
@@ -0,0 +1,120 @@
+package lgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	path := writeConfig(t, "log.yaml", `
+encoding: json
+level: DEBUG
+levels: "db=warn"
+time_key: ""
+disable_caller: true
+output_paths: [`+logFile+`]
+initial_fields: [app, hello, pid, 42]
+sampling: true
+sampling_tick: 500ms
+async_overflow: block
+bad_key_policy: drop
+rotation:
+  max_size: 10
+  compress: true
+trace_keys:
+  trace_id: trace.id
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.SamplingTick != 500*time.Millisecond || cfg.AsyncOverflow != OverflowBlock || cfg.BadKeyPolicy != BadKeyDrop {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if cfg.Rotation == nil || cfg.Rotation.MaxSize != 10 || !cfg.Rotation.Compress {
+		t.Errorf("unexpected rotation %+v", cfg.Rotation)
+	}
+	// the keys not in the file keep their default values
+	if cfg.TraceKeys.TraceID != "trace.id" || cfg.TraceKeys.SpanID != "span_id" || cfg.AsyncBufferSize != 4096 {
+		t.Errorf("expect default values to be kept, got %+v", cfg)
+	}
+
+	log, err := NewFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Debug("loaded", "uid", 7)
+	log.Named("db").Info("invisible")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"level":"debug","msg":"loaded","app":"hello","pid":42,"uid":7}`
+	if got := strings.TrimSpace(string(data)); got != expect {
+		t.Errorf("expect %s, got %s", expect, got)
+	}
+}
+
+func TestLoadConfigJSON(t *testing.T) {
+	path := writeConfig(t, "log.json", `{
+	"encoding": "console",
+	"level": "warning",
+	"async": true,
+	"async_flush_interval": "2s"
+}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Encoding != "console" || cfg.Level != "warning" || !cfg.Async || cfg.AsyncFlushInterval != 2*time.Second {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	path := writeConfig(t, "log.yaml", "level: info\nencoding: yaml\nbad_key_policy: fold\nasync: true\nasync_buffer_size: -1\n")
+	_, err := LoadConfig(path)
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) || len(cfgErr.Errors) != 2 {
+		t.Fatalf("expect 2 invalid fields, got %v", err)
+	}
+	if fe := cfgErr.Errors[0]; fe.Key != "encoding" || fe.Line != 2 {
+		t.Errorf("expect error on key encoding line 2, got %+v", fe)
+	}
+	if fe := cfgErr.Errors[1]; fe.Key != "async_buffer_size" || fe.Line != 5 {
+		t.Errorf("expect error on key async_buffer_size line 5, got %+v", fe)
+	}
+	if !strings.Contains(err.Error(), `invalid encoding (line 2) "yaml"`) {
+		t.Errorf("expect the error to point to the key, got %v", err)
+	}
+
+	for name, content := range map[string]string{
+		"unknown.yaml": "levle: debug\n",
+		"policy.yaml":  "async_overflow: drop_all\n",
+		"type.json":    `{"sampling_initial": "many"}`,
+		"log.toml":     "level = \"debug\"\n",
+	} {
+		if _, err := LoadConfig(writeConfig(t, name, content)); err == nil {
+			t.Errorf("expect error for %s", name)
+		}
+	}
+}
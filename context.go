@@ -0,0 +1,87 @@
+package lgr
+
+import (
+	"context"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type ctxKey struct{}
+
+// ContextExtractor returns the key, value pairs to add to the entries logged with the context,
+// e.g. the request ID, tenant or user carried by the context.
+// it returns nil if the context carries nothing of interest.
+type ContextExtractor func(ctx context.Context) []interface{}
+
+// NewContext returns a copy of ctx carrying the logger, retrieve it with FromContext
+func NewContext(ctx context.Context, l *LogImpl) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or the global logger S() if there is none
+func FromContext(ctx context.Context) *LogImpl {
+	if ctx != nil {
+		if l, ok := ctx.Value(ctxKey{}).(*LogImpl); ok && l != nil {
+			return l
+		}
+	}
+	return S()
+}
+
+// enabled reports whether an entry at lvl is written by the logger,
+// so the *Ctx methods skip the context extraction for the filtered entries
+func (l *LogImpl) enabled(lvl zapcore.Level) bool {
+	return l.z.Core().Enabled(lvl) && lvl >= l.levels.levelFor(l.name)
+}
+
+// contextKeysAndValues prepends the trace correlation pairs and the pairs extracted from ctx to keysAndValues,
+// then applies the BadKeyPolicy
+func (l *LogImpl) contextKeysAndValues(ctx context.Context, keysAndValues []interface{}) []interface{} {
+	if ctx == nil {
+		return l.normalizePairs(keysAndValues)
+	}
+
+	extracted := l.traceKeysAndValues(ctx)
+	for _, extract := range l.ContextExtractors {
+		extracted = append(extracted, extract(ctx)...)
+	}
+	if len(extracted) == 0 {
+		return l.normalizePairs(keysAndValues)
+	}
+	return l.normalizePairs(append(extracted, keysAndValues...))
+}
+
+// DebugCtx is like Debug, with the fields extracted from ctx by the registered ContextExtractors.
+// if ctx carries an OpenTelemetry span, the trace correlation fields named by TraceKeys are added too.
+func (l *LogImpl) DebugCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	if !l.enabled(zapcore.DebugLevel) {
+		return
+	}
+	l.s.Debugw(msg, l.contextKeysAndValues(ctx, keysAndValues)...)
+}
+
+// InfoCtx is like Info, with the fields extracted from ctx by the registered ContextExtractors
+func (l *LogImpl) InfoCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	if !l.enabled(zapcore.InfoLevel) {
+		return
+	}
+	l.s.Infow(msg, l.contextKeysAndValues(ctx, keysAndValues)...)
+}
+
+// WarnCtx is like Warn, with the fields extracted from ctx by the registered ContextExtractors
+func (l *LogImpl) WarnCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	if !l.enabled(zapcore.WarnLevel) {
+		return
+	}
+	l.s.Warnw(msg, l.contextKeysAndValues(ctx, keysAndValues)...)
+}
+
+// ErrorCtx is like Error, with the fields extracted from ctx by the registered ContextExtractors,
+// the entry is also added as an event to the span carried by ctx if enabled by WithSpanEvents
+func (l *LogImpl) ErrorCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	if !l.enabled(zapcore.ErrorLevel) {
+		return
+	}
+	l.s.Errorw(msg, l.contextKeysAndValues(ctx, keysAndValues)...)
+	l.addSpanEvent(ctx, "error", msg, keysAndValues)
+}
@@ -0,0 +1,64 @@
+package lgr
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+type requestIDKey struct{}
+
+func requestIDExtractor(ctx context.Context) []interface{} {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return []interface{}{"request_id", id}
+	}
+	return nil
+}
+
+func TestCtxMethods(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithLevel("debug"), WithCustomSink(buf), WithTimeKey(""), WithContextExtractors(requestIDExtractor))
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-001")
+	ctx = NewContext(ctx, log.Named("handler"))
+
+	FromContext(ctx).DebugCtx(ctx, "debug message", "uid", 7)
+	FromContext(ctx).InfoCtx(ctx, "info message")
+	FromContext(ctx).WarnCtx(context.Background(), "warn message without request id")
+	FromContext(ctx).ErrorCtx(ctx, "error message", "uid", 8)
+
+	expect := `{"level":"debug","logger":"handler","caller":"lgr/context_test.go:25","msg":"debug message","request_id":"req-001","uid":7}
+{"level":"info","logger":"handler","caller":"lgr/context_test.go:26","msg":"info message","request_id":"req-001"}
+{"level":"warn","logger":"handler","caller":"lgr/context_test.go:27","msg":"warn message without request id"}
+{"level":"error","logger":"handler","caller":"lgr/context_test.go:28","msg":"error message","request_id":"req-001","uid":8}
+`
+	if buf.String() != expect {
+		t.Errorf("unexpected output:\n%s\nexpect:\n%s", buf.String(), expect)
+	}
+}
+
+func TestFromContextFallbackToGlobal(t *testing.T) {
+	if FromContext(context.Background()) != S() {
+		t.Errorf("expect the global logger if ctx carries no logger")
+	}
+}
+
+func TestCtxMethodsSkipDisabledLevels(t *testing.T) {
+	extracted := 0
+	countingExtractor := func(ctx context.Context) []interface{} {
+		extracted++
+		return nil
+	}
+	log := NewLogger(WithLevels("db=debug"), WithCustomSink(bytes.NewBuffer(nil)), WithContextExtractors(countingExtractor))
+
+	log.DebugCtx(context.Background(), "filtered")
+	log.Named("http").DebugCtx(context.Background(), "filtered")
+	if extracted != 0 {
+		t.Errorf("expect no extraction for the filtered entries, got %d", extracted)
+	}
+	log.Named("db").DebugCtx(context.Background(), "written")
+	log.InfoCtx(context.Background(), "written")
+	if extracted != 2 {
+		t.Errorf("expect 2 extractions, got %d", extracted)
+	}
+}
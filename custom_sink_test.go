@@ -0,0 +1,90 @@
+package lgr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCustomSinksWithOutputPaths(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	pane, capture := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	log := NewLogger(WithOutputPaths(path), WithCustomSink(pane), WithCustomSink(capture),
+		WithTimeKey(""), WithDisableCaller(true))
+
+	log.Info("info message", "uid", 7)
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `{"level":"info","msg":"info message","uid":7}` + "\n"
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, got := range map[string]string{"file": string(data), "pane": pane.String(), "capture": capture.String()} {
+		if got != expect {
+			t.Errorf("%s: expect %q, got %q", name, expect, got)
+		}
+	}
+}
+
+func TestCustomSinksWithOutputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "error.log")
+	pane := bytes.NewBuffer(nil)
+	log := NewLogger(WithCustomSink(pane), WithTimeKey(""), WithDisableCaller(true),
+		WithOutputs(OutputConfig{Paths: []string{path}, Level: "error"}))
+
+	log.Info("info message")
+	log.Error("error message")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := strings.Count(pane.String(), "\n"); lines != 2 {
+		t.Errorf("pane: expect 2 lines, got %d: %q", lines, pane)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := `{"level":"error","msg":"error message"}` + "\n"; string(data) != expect {
+		t.Errorf("expect %q, got %q", expect, data)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestCustomErrorSink(t *testing.T) {
+	errBuf := bytes.NewBuffer(nil)
+	log := NewLogger(WithCustomSink(failingWriter{}), WithCustomErrorSink(errBuf))
+
+	log.Info("info message")
+	if !strings.Contains(errBuf.String(), "disk full") {
+		t.Errorf("expect the write error in the custom error sink, got %q", errBuf)
+	}
+}
+
+func TestDeprecatedCustomSink(t *testing.T) {
+	old, added := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	cfg := DefaultConfig()
+	cfg.CustomSink = old
+	cfg.TimeKey = ""
+	cfg.DisableCaller = true
+	log, err := NewFromConfig(cfg, WithCustomSink(added))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log.Info("info message")
+	expect := `{"level":"info","msg":"info message"}` + "\n"
+	if old.String() != expect || added.String() != expect {
+		t.Errorf("expect %q in both sinks, got %q and %q", expect, old, added)
+	}
+}
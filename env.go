@@ -0,0 +1,89 @@
+package lgr
+
+import (
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envAliases maps the short environment variable suffixes to the Config field names
+var envAliases = map[string]string{
+	"OUTPUT":       "OutputPaths",
+	"ERROR_OUTPUT": "ErrorOutputPaths",
+}
+
+// WithEnv overlays the config with the environment variables named prefix_KEY,
+// where KEY is the upper case config file key, e.g. MYAPP_LOG_LEVEL or MYAPP_LOG_ASYNC_BUFFER_SIZE, see LoadConfig.
+// MYAPP_LOG_OUTPUT and MYAPP_LOG_ERROR_OUTPUT are short for MYAPP_LOG_OUTPUT_PATHS and MYAPP_LOG_ERROR_OUTPUT_PATHS.
+// lists are comma separated, structs use the YAML flow syntax, e.g. MYAPP_LOG_ROTATION="{max_size: 10, compress: true}".
+// the options after WithEnv take precedence, an invalid value is reported by NewLoggerE.
+func WithEnv(prefix string) Option {
+	return func(l *LogImpl) {
+		errs := l.Config.overlayEnv(prefix, os.LookupEnv)
+		l.optionErrs = append(l.optionErrs, errs.Errors...)
+	}
+}
+
+func (cfg *Config) overlayEnv(prefix string, lookup func(string) (string, bool)) *ConfigError {
+	errs := &ConfigError{}
+	prefix = strings.TrimSuffix(prefix, "_") + "_"
+
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	// in field order, so the errors are reported in a stable order
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i).Name
+		key, ok := configKeys[field]
+		if !ok {
+			continue
+		}
+		suffixes := []string{strings.ToUpper(key)}
+		for alias, aliased := range envAliases {
+			if aliased == field {
+				suffixes = append(suffixes, alias)
+			}
+		}
+		for _, suffix := range suffixes {
+			name := prefix + suffix
+			val, ok := lookup(name)
+			if !ok {
+				continue
+			}
+			if err := setFromEnv(v.Field(i), val); err != nil {
+				errs.add(field, val, "%s: %w", name, err)
+			}
+		}
+	}
+	return errs
+}
+
+// setFromEnv sets the field from an environment variable value
+func setFromEnv(f reflect.Value, val string) error {
+	switch {
+	case f.Kind() == reflect.String:
+		// as is, so an empty value e.g. disables the time key
+		f.SetString(val)
+		return nil
+	case f.Type() == reflect.TypeOf([]string(nil)):
+		var list []string
+		for _, item := range strings.Split(val, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		f.Set(reflect.ValueOf(list))
+		return nil
+	}
+	// the same syntax as the config files, e.g. "true", "1s" or "drop_oldest",
+	// and like LoadConfig the unknown keys of a struct are rejected, e.g. "{max_sise: 10}"
+	dec := yaml.NewDecoder(strings.NewReader(val))
+	dec.KnownFields(true)
+	if err := dec.Decode(f.Addr().Interface()); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
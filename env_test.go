@@ -0,0 +1,62 @@
+package lgr
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithEnv(t *testing.T) {
+	t.Setenv("MYAPP_LOG_LEVEL", "debug")
+	t.Setenv("MYAPP_LOG_LEVELS", "db=warn")
+	t.Setenv("MYAPP_LOG_TIME_KEY", "")
+	t.Setenv("MYAPP_LOG_DISABLE_CALLER", "true")
+	t.Setenv("MYAPP_LOG_OUTPUT", "stdout, stderr")
+	t.Setenv("MYAPP_LOG_SAMPLING_TICK", "250ms")
+	t.Setenv("MYAPP_LOG_ASYNC_OVERFLOW", "drop_oldest")
+	t.Setenv("MYAPP_LOG_ROTATION", "{max_size: 10, compress: true}")
+	t.Setenv("MYAPP_LOG_INITIAL_FIELDS", "[app, hello, pid, 42]")
+
+	buf := bytes.NewBuffer([]byte(""))
+	// the options after WithEnv take precedence
+	log := NewLogger(WithEnv("MYAPP_LOG"), WithCustomSink(buf))
+
+	cfg := log.Config
+	if len(cfg.OutputPaths) != 2 || cfg.OutputPaths[0] != "stdout" || cfg.OutputPaths[1] != "stderr" {
+		t.Errorf("unexpected output paths %q", cfg.OutputPaths)
+	}
+	if cfg.SamplingTick != 250*time.Millisecond || cfg.AsyncOverflow != OverflowDropOldest {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if cfg.Rotation == nil || cfg.Rotation.MaxSize != 10 || !cfg.Rotation.Compress {
+		t.Errorf("unexpected rotation %+v", cfg.Rotation)
+	}
+
+	log.Debug("from env")
+	log.Named("db").Info("invisible")
+	expect := `{"level":"debug","msg":"from env","app":"hello","pid":42}`
+	if got := strings.TrimSpace(buf.String()); got != expect {
+		t.Errorf("expect %s, got %s", expect, got)
+	}
+}
+
+func TestWithEnvInvalid(t *testing.T) {
+	t.Setenv("MYAPP_LOG_SAMPLING_TICK", "fast")
+	t.Setenv("MYAPP_LOG_ASYNC", "maybe")
+	t.Setenv("MYAPP_LOG_ENCODING", "yaml")
+	t.Setenv("MYAPP_LOG_ROTATION", "{max_sise: 10}")
+
+	_, err := NewLoggerE(WithEnv("MYAPP_LOG_"))
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) || len(cfgErr.Errors) != 4 {
+		t.Fatalf("expect 4 invalid fields, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "field max_sise not found") {
+		t.Errorf("expect the unknown key reported, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "MYAPP_LOG_SAMPLING_TICK") {
+		t.Errorf("expect the error to name the environment variable, got %v", err)
+	}
+}
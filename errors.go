@@ -0,0 +1,87 @@
+package lgr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FieldError describes an invalid Config field
+type FieldError struct {
+	Field string      // the Config field name, e.g. "Encoding"
+	Key   string      // the config file key of the field, e.g. "encoding"
+	Line  int         // the line of the key in the config file, set by LoadConfig
+	Value interface{} // the invalid value
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	name := e.Field
+	if e.Line > 0 {
+		// point to the offending key of the config file
+		name = fmt.Sprintf("%s (line %d)", e.Key, e.Line)
+	}
+	if s, ok := e.Value.(string); ok {
+		return fmt.Sprintf("invalid %s %q: %v", name, s, e.Err)
+	}
+	return fmt.Sprintf("invalid %s %v: %v", name, e.Value, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigError lists all the invalid Config fields, it is returned by NewLoggerE
+type ConfigError struct {
+	Errors []*FieldError
+}
+
+func (e *ConfigError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return "invalid logger config: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the field errors, so errors.As can find a *FieldError
+func (e *ConfigError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		errs = append(errs, fe)
+	}
+	return errs
+}
+
+// As finds the first field error matching target, errors.As follows Unwrap() []error only since go 1.20
+func (e *ConfigError) As(target interface{}) bool {
+	for _, fe := range e.Errors {
+		if errors.As(fe, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Is reports whether a field error matches target, errors.Is follows Unwrap() []error only since go 1.20
+func (e *ConfigError) Is(target error) bool {
+	for _, fe := range e.Errors {
+		if errors.Is(fe, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// add records an invalid field
+func (e *ConfigError) add(field string, value interface{}, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, &FieldError{Field: field, Key: configKeys[field], Value: value, Err: fmt.Errorf(format, args...)})
+}
+
+// errOrNil returns nil if there is no invalid field, so the result can be compared with nil
+func (e *ConfigError) errOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,65 @@
+package lgr
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewLoggerEReportsAllInvalidOptions(t *testing.T) {
+	log, err := NewLoggerE(
+		WithEncoding("consolexxx"),
+		WithLevels("db"),
+		WithInitialFields("only_key_no_value"),
+		WithAsync(0, 0, OverflowBlock),
+	)
+	if log != nil {
+		t.Errorf("expect nil logger on error")
+	}
+
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("expect *ConfigError, got %T: %v", err, err)
+	}
+	var fields []string
+	for _, fe := range cfgErr.Errors {
+		fields = append(fields, fe.Field)
+	}
+	expect := []string{"Encoding", "Levels", "InitialFields", "AsyncBufferSize"}
+	if len(fields) != len(expect) {
+		t.Fatalf("expect invalid fields %v, got %v", expect, fields)
+	}
+	for i := range expect {
+		if fields[i] != expect[i] {
+			t.Errorf("expect invalid fields %v, got %v", expect, fields)
+		}
+	}
+	t.Logf("err=%v", err)
+}
+
+func TestNewLoggerEInvalidOutputPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not-exist", "app.log")
+	_, err := NewLoggerE(WithOutputPaths(path))
+
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Field != "OutputPaths" {
+		t.Fatalf("expect OutputPaths field error, got %v", err)
+	}
+}
+
+func TestConfigErrorAs(t *testing.T) {
+	_, err := NewLoggerE(WithOutputPaths(filepath.Join(t.TempDir(), "not-exist", "app.log")))
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("expect *ConfigError, got %T: %v", err, err)
+	}
+
+	// call As and Is directly, as errors.As and errors.Is do before go 1.20
+	var fe *FieldError
+	if !cfgErr.As(&fe) || fe.Field != "OutputPaths" {
+		t.Errorf("expect OutputPaths field error, got %v", fe)
+	}
+	if !cfgErr.Is(fe) || cfgErr.Is(errors.New("other")) {
+		t.Errorf("expect Is to match only the field errors")
+	}
+}
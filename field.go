@@ -0,0 +1,69 @@
+package lgr
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field is a strongly typed key value pair, see the *F logging methods.
+// the typed fields skip the reflection of the loosely typed key value pairs and need fewer allocations,
+// the variadic slice of the fields is still allocated, see BenchmarkInfoTypedFields.
+// the constructors below cover the common types, any zap field constructor can be used as well.
+type Field = zap.Field
+
+func Any(key string, val interface{}) Field                { return zap.Any(key, val) }
+func Bool(key string, val bool) Field                      { return zap.Bool(key, val) }
+func Int(key string, val int) Field                        { return zap.Int(key, val) }
+func Int64(key string, val int64) Field                    { return zap.Int64(key, val) }
+func Uint(key string, val uint) Field                      { return zap.Uint(key, val) }
+func Uint64(key string, val uint64) Field                  { return zap.Uint64(key, val) }
+func Float64(key string, val float64) Field                { return zap.Float64(key, val) }
+func String(key string, val string) Field                  { return zap.String(key, val) }
+func ByteString(key string, val []byte) Field              { return zap.ByteString(key, val) }
+func Stringer(key string, val fmt.Stringer) Field          { return zap.Stringer(key, val) }
+func Duration(key string, val time.Duration) Field         { return zap.Duration(key, val) }
+func Time(key string, val time.Time) Field                 { return zap.Time(key, val) }
+func Object(key string, val zapcore.ObjectMarshaler) Field { return zap.Object(key, val) }
+
+// Err adds the error with key "error", nothing is added for a nil error
+func Err(err error) Field { return zap.Error(err) }
+
+// NamedErr adds the error with the given key, nothing is added for a nil error
+func NamedErr(key string, err error) Field { return zap.NamedError(key, err) }
+
+func (l *LogImpl) TraceF(msg string, fields ...Field) {
+	if ce := l.z.Check(TraceLevel, msg); ce != nil {
+		ce.Write(fields...)
+	}
+}
+
+func (l *LogImpl) DebugF(msg string, fields ...Field) {
+	l.z.Debug(msg, fields...)
+}
+
+func (l *LogImpl) InfoF(msg string, fields ...Field) {
+	l.z.Info(msg, fields...)
+}
+
+func (l *LogImpl) WarnF(msg string, fields ...Field) {
+	l.z.Warn(msg, fields...)
+}
+
+func (l *LogImpl) ErrorF(msg string, fields ...Field) {
+	l.z.Error(msg, fields...)
+}
+
+func (l *LogImpl) FatalF(msg string, fields ...Field) {
+	l.z.Fatal(msg, fields...)
+}
+
+// WithFields return a new LogImpl with typed context fields
+func (l *LogImpl) WithFields(fields ...Field) *LogImpl {
+	newLgr := l.clone()
+	newLgr.z = l.z.With(fields...)
+	newLgr.s = newLgr.z.Sugar()
+	return newLgr
+}
@@ -0,0 +1,108 @@
+package lgr
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type buildInfo struct {
+	Commit string
+	Dirty  bool
+}
+
+func (b buildInfo) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("commit", b.Commit)
+	enc.AddBool("dirty", b.Dirty)
+	return nil
+}
+
+func TestInitialFieldsKeepTypes(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithTimeKey(""), WithDisableCaller(true),
+		WithInitialFields("app", "hello", "pid", 42, "canary", true, zap.Object("build", buildInfo{Commit: "8ba2e21"})))
+	log.Info("started")
+
+	expect := `{"level":"info","msg":"started","app":"hello","pid":42,"canary":true,"build":{"commit":"8ba2e21","dirty":false}}`
+	if got := strings.TrimSpace(buf.String()); got != expect {
+		t.Errorf("expect %s, got %s", expect, got)
+	}
+}
+
+func TestInvalidInitialFields(t *testing.T) {
+	for _, kv := range [][]interface{}{
+		{"only_key_no_value"},
+		{42, "value"},
+		{zap.Int("pid", 42), "dangling"},
+	} {
+		_, err := NewLoggerE(WithInitialFields(kv...))
+		var fe *FieldError
+		if !errors.As(err, &fe) || fe.Field != "InitialFields" {
+			t.Errorf("expect InitialFields field error for %v, got %v", kv, err)
+		}
+	}
+}
+
+func TestTypedFields(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithTimeKey(""), WithLevel("trace"))
+	child := log.Named("db").WithFields(String("table", "users"))
+
+	child.TraceF("frame", Int("len", 42))
+	child.InfoF("query done", Duration("took", 1500*time.Millisecond), Bool("cached", false), Err(nil))
+	child.ErrorF("query failed", Err(errors.New("timeout")), Uint64("rows", 0))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expect 3 lines, got %q", buf.String())
+	}
+	for i, suffix := range []string{
+		`"msg":"frame","table":"users","len":42}`,
+		`"msg":"query done","table":"users","took":1.5,"cached":false}`,
+		`"msg":"query failed","table":"users","error":"timeout","rows":0}`,
+	} {
+		if !strings.Contains(lines[i], `"logger":"db","caller":"lgr/field_test.go:`) || !strings.HasSuffix(lines[i], suffix) {
+			t.Errorf("unexpected line %s", lines[i])
+		}
+	}
+}
+
+func TestTypedFieldsAllocateLess(t *testing.T) {
+	log := NewLogger(WithCustomSink(io.Discard), WithTimeKey(""), WithDisableCaller(true))
+	status := 1000
+	sugared := testing.AllocsPerRun(100, func() {
+		status++
+		log.Info("request done", "path", "/", "status", status, "took", time.Duration(status))
+	})
+	typed := testing.AllocsPerRun(100, func() {
+		status++
+		log.InfoF("request done", String("path", "/"), Int("status", status), Duration("took", time.Duration(status)))
+	})
+	if typed >= sugared {
+		t.Errorf("expect typed fields to allocate less than key value pairs, got %v >= %v", typed, sugared)
+	}
+}
+
+func BenchmarkInfoKeysAndValues(b *testing.B) {
+	log := NewLogger(WithCustomSink(io.Discard), WithTimeKey(""), WithDisableCaller(true))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Info("request done", "path", "/", "status", i, "took", time.Duration(i))
+	}
+}
+
+func BenchmarkInfoTypedFields(b *testing.B) {
+	log := NewLogger(WithCustomSink(io.Discard), WithTimeKey(""), WithDisableCaller(true))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.InfoF("request done", String("path", "/"), Int("status", i), Duration("took", time.Duration(i)))
+	}
+}
@@ -0,0 +1,269 @@
+package lgr
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/ttys3/lgr/internal/bufferpool"
+)
+
+const (
+	// JournaldScheme is the url scheme of the systemd journald sink, which uses the native protocol,
+	// e.g. journald:// sends to the default socket and journald:///run/systemd/journal/socket to the given one.
+	// the prefix url parameter is prepended to the field names, e.g. journald://?prefix=myapp_
+	// turns the user_id field into MYAPP_USER_ID.
+	JournaldScheme = "journald"
+
+	defaultJournaldSocket = "/run/systemd/journal/socket"
+	maxJournalFieldName   = 64
+)
+
+// the journal fields written by journaldCore, a user field with one of these names is prefixed with F_
+var journalEntryFields = map[string]bool{
+	"MESSAGE": true, "PRIORITY": true, "SYSLOG_IDENTIFIER": true, "SYSLOG_TIMESTAMP": true,
+	"CODE_FILE": true, "CODE_LINE": true, "CODE_FUNC": true,
+}
+
+// isJournaldPath reports whether the output path is a journald url
+func isJournaldPath(path string) bool {
+	u, err := url.Parse(path)
+	return err == nil && u.Scheme == JournaldScheme
+}
+
+// journaldConfig is parsed from a journald url
+type journaldConfig struct {
+	socket string
+	prefix string
+}
+
+func parseJournaldURL(path string) (journaldConfig, error) {
+	jc := journaldConfig{socket: defaultJournaldSocket}
+	u, err := url.Parse(path)
+	if err != nil {
+		return jc, err
+	}
+	if u.Scheme != JournaldScheme {
+		return jc, fmt.Errorf("unknown journald url scheme %q", u.Scheme)
+	}
+	if u.Host != "" {
+		return jc, fmt.Errorf("%s url %q must have an absolute path, e.g. %s:///run/systemd/journal/socket", JournaldScheme, path, JournaldScheme)
+	}
+	if u.Path != "" && u.Path != "/" {
+		jc.socket = u.Path
+	}
+	jc.prefix = u.Query().Get("prefix")
+	return jc, nil
+}
+
+var _ messageWriter = (*journaldWriter)(nil)
+
+// journaldWriter sends one journal entry per write, the connection is redialed once if a write fails,
+// then with a backoff if the dial fails too
+type journaldWriter struct {
+	cfg journaldConfig
+
+	mu     sync.Mutex
+	conn   *net.UnixConn
+	closed bool
+	redial redialer
+}
+
+func newJournaldWriter(path string) (*journaldWriter, error) {
+	cfg, err := parseJournaldURL(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &journaldWriter{cfg: cfg, redial: redialer{sink: "journald"}}
+	if err := w.dial(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *journaldWriter) Write(msg []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, errors.New("journald sink is closed")
+	}
+	if w.conn != nil {
+		if err := w.send(msg); err == nil {
+			return len(msg), nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	} else if w.redial.wait() {
+		// the failed dial has been reported, the entry is only counted
+		return len(msg), nil
+	}
+	if err := w.dial(); err != nil {
+		w.redial.failed()
+		return 0, multierr.Append(err, w.redial.droppedErr())
+	}
+	w.redial.connected()
+	if err := w.send(msg); err != nil {
+		return 0, err
+	}
+	// the entry is sent, the entries dropped while disconnected are still reported
+	return len(msg), w.redial.droppedErr()
+}
+
+// Sync does nothing, each entry is sent by its write
+func (w *journaldWriter) Sync() error {
+	return nil
+}
+
+func (w *journaldWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *journaldWriter) core(cfg *Config, _ zapcore.Encoder, enabler zapcore.LevelEnabler) zapcore.Core {
+	return &journaldCore{
+		LevelEnabler: enabler,
+		w:            w,
+		identifier:   cfg.appName(),
+	}
+}
+
+var _ zapcore.Core = (*journaldCore)(nil)
+
+// journaldCore sends each entry with its PRIORITY, SYSLOG_IDENTIFIER, SYSLOG_TIMESTAMP and caller,
+// the fields are promoted to journal fields with uppercase names, the output encoding is not used
+type journaldCore struct {
+	zapcore.LevelEnabler
+	w          *journaldWriter
+	fields     []zapcore.Field
+	identifier string
+}
+
+func (c *journaldCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.fields = make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	clone.fields = append(clone.fields, c.fields...)
+	clone.fields = append(clone.fields, fields...)
+	return &clone
+}
+
+func (c *journaldCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *journaldCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf := bufferpool.Get()
+	defer buf.Free()
+
+	msg := ent.Message
+	if ent.Stack != "" {
+		msg += "\n" + ent.Stack
+	}
+	appendJournalField(buf, "MESSAGE", msg)
+	appendJournalField(buf, "PRIORITY", strconv.Itoa(syslogSeverity(ent.Level)))
+	appendJournalField(buf, "SYSLOG_IDENTIFIER", c.identifier)
+	if !ent.Time.IsZero() {
+		// journald stamps the entries on arrival, the time of the entry is sent as well
+		appendJournalField(buf, "SYSLOG_TIMESTAMP", ent.Time.Format(time.RFC3339Nano))
+	}
+	if ent.Caller.Defined {
+		appendJournalField(buf, "CODE_FILE", ent.Caller.File)
+		appendJournalField(buf, "CODE_LINE", strconv.Itoa(ent.Caller.Line))
+		if ent.Caller.Function != "" {
+			appendJournalField(buf, "CODE_FUNC", ent.Caller.Function)
+		}
+	}
+
+	m := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(m)
+	}
+	for _, f := range fields {
+		f.AddTo(m)
+	}
+	if ent.LoggerName != "" {
+		m.Fields["logger"] = ent.LoggerName
+	}
+	names := make([]string, 0, len(m.Fields))
+	for name := range m.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := journalFieldName(c.w.cfg.prefix + name)
+		if journalEntryFields[field] {
+			field = "F_" + field
+		}
+		appendJournalField(buf, field, fieldString(m.Fields[name]))
+	}
+
+	_, err := c.w.Write(buf.Bytes())
+	return err
+}
+
+func (c *journaldCore) Sync() error {
+	return c.w.Sync()
+}
+
+// appendJournalField appends a field in the native protocol format,
+// a value with newlines is prefixed with its little endian 64 bit length
+func appendJournalField(buf *buffer.Buffer, name, value string) {
+	buf.AppendString(name)
+	if !strings.Contains(value, "\n") {
+		buf.AppendByte('=')
+		buf.AppendString(value)
+		buf.AppendByte('\n')
+		return
+	}
+	buf.AppendByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	buf.Write(size[:])
+	buf.AppendString(value)
+	buf.AppendByte('\n')
+}
+
+// journalFieldName turns a field name into a journal field name,
+// which has only uppercase letters, digits and underscores, and starts with a letter
+func journalFieldName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	// the names starting with an underscore are reserved for the trusted fields added by journald
+	name = strings.TrimLeft(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "F_" + name
+	}
+	if len(name) > maxJournalFieldName {
+		name = name[:maxJournalFieldName]
+	}
+	return name
+}
@@ -0,0 +1,49 @@
+package lgr
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+)
+
+// dial opens an unbound socket, the entries are addressed to the journald socket,
+// as a connected datagram socket cannot pass file descriptors
+func (w *journaldWriter) dial() error {
+	if _, err := os.Stat(w.cfg.socket); err != nil {
+		return err
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+// send sends the entry as a datagram, an entry too large for a datagram is written to an unlinked file
+// whose descriptor is passed to journald instead
+func (w *journaldWriter) send(msg []byte) error {
+	addr := &net.UnixAddr{Name: w.cfg.socket, Net: "unixgram"}
+	_, _, err := w.conn.WriteMsgUnix(msg, nil, addr)
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return err
+	}
+
+	f, err := os.CreateTemp("/dev/shm", "lgr-journal-")
+	if err != nil {
+		if f, err = os.CreateTemp("", "lgr-journal-"); err != nil {
+			return err
+		}
+	}
+	defer f.Close()
+	// journald only accepts files which are not linked anywhere
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+	if _, err := f.Write(msg); err != nil {
+		return err
+	}
+	_, _, err = w.conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), addr)
+	return err
+}
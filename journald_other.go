@@ -0,0 +1,15 @@
+//go:build !linux
+
+package lgr
+
+import "errors"
+
+var errJournaldUnsupported = errors.New("journald is only supported on linux")
+
+func (w *journaldWriter) dial() error {
+	return errJournaldUnsupported
+}
+
+func (w *journaldWriter) send([]byte) error {
+	return errJournaldUnsupported
+}
@@ -0,0 +1,203 @@
+//go:build linux
+
+package lgr
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// listenJournald listens on a fake journald socket, the unix socket paths are limited to about 100 chars
+func listenJournald(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "lgr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, path
+}
+
+// readJournalEntry reads a native protocol entry, from the datagram or from the passed file
+func readJournalEntry(t *testing.T, conn *net.UnixConn) map[string]string {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf, oob := make([]byte, 64*1024), make([]byte, 128)
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := buf[:n]
+	if oobn > 0 {
+		msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+		if err != nil {
+			t.Fatal(err)
+		}
+		fds, err := syscall.ParseUnixRights(&msgs[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := os.NewFile(uintptr(fds[0]), "journal")
+		defer f.Close()
+		f.Seek(0, io.SeekStart)
+		if data, err = io.ReadAll(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fields := map[string]string{}
+	for len(data) > 0 {
+		line := data[:bytes.IndexByte(data, '\n')]
+		if i := bytes.IndexByte(line, '='); i >= 0 {
+			fields[string(line[:i])] = string(line[i+1:])
+			data = data[len(line)+1:]
+			continue
+		}
+		data = data[len(line)+1:]
+		size := binary.LittleEndian.Uint64(data)
+		fields[string(line)] = string(data[8 : 8+size])
+		data = data[8+size+1:]
+	}
+	return fields
+}
+
+func TestJournald(t *testing.T) {
+	conn, path := listenJournald(t)
+	log := NewLogger(WithName("myapp"), WithOutputPaths("journald://"+path+"?prefix=myapp_"))
+	log.With("user_id", 42).Warn("user login", "note", "line1\nline2", "tags", []string{"a", "b"})
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := readJournalEntry(t, conn)
+	for name, expect := range map[string]string{
+		"MESSAGE":           "user login",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "myapp",
+		"MYAPP_USER_ID":     "42",
+		"MYAPP_NOTE":        "line1\nline2",
+		"MYAPP_TAGS":        `["a","b"]`,
+		"MYAPP_LOGGER":      "myapp",
+	} {
+		if fields[name] != expect {
+			t.Errorf("%s: expect %q, got %q", name, expect, fields[name])
+		}
+	}
+	if ts, err := time.Parse(time.RFC3339Nano, fields["SYSLOG_TIMESTAMP"]); err != nil || time.Since(ts) > time.Minute {
+		t.Errorf("expect the entry time, got %q, %v", fields["SYSLOG_TIMESTAMP"], err)
+	}
+	if !strings.HasSuffix(fields["CODE_FILE"], "journald_test.go") || fields["CODE_LINE"] == "" || !strings.HasSuffix(fields["CODE_FUNC"], ".TestJournald") {
+		t.Errorf("unexpected caller fields %q %q %q", fields["CODE_FILE"], fields["CODE_LINE"], fields["CODE_FUNC"])
+	}
+}
+
+func TestJournaldLargeEntry(t *testing.T) {
+	conn, path := listenJournald(t)
+	log := NewLogger(WithDisableCaller(true), WithOutputPaths("journald://"+path))
+	large := strings.Repeat("x", 1024*1024)
+	log.Info("large entry", "payload", large)
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := readJournalEntry(t, conn)
+	if fields["MESSAGE"] != "large entry" || fields["PAYLOAD"] != large {
+		t.Errorf("unexpected large entry, message %q, payload of %d bytes", fields["MESSAGE"], len(fields["PAYLOAD"]))
+	}
+}
+
+func TestJournalFieldName(t *testing.T) {
+	for name, expect := range map[string]string{
+		"user_id":               "USER_ID",
+		"http.status":           "HTTP_STATUS",
+		"_trusted":              "TRUSTED",
+		"2fa":                   "F_2FA",
+		"":                      "F_",
+		strings.Repeat("a", 70): strings.Repeat("A", 64),
+	} {
+		if got := journalFieldName(name); got != expect {
+			t.Errorf("%q: expect %q, got %q", name, expect, got)
+		}
+	}
+}
+
+func TestParseJournaldURL(t *testing.T) {
+	jc, err := parseJournaldURL("journald://")
+	if err != nil || jc.socket != defaultJournaldSocket {
+		t.Errorf("expect the default socket, got %+v, %v", jc, err)
+	}
+	if _, err := parseJournaldURL("journald://run/systemd/journal/socket"); err == nil {
+		t.Error("expect error for a relative socket path")
+	}
+	if _, err := NewLoggerE(WithOutputPaths("journald:///nonexistent/journal.sock")); err == nil || !strings.Contains(err.Error(), "open journald") {
+		t.Errorf("expect open journald error, got %v", err)
+	}
+}
+
+func TestJournaldReservedFields(t *testing.T) {
+	conn, path := listenJournald(t)
+	log := NewLogger(WithName("myapp"), WithDisableCaller(true), WithOutputPaths("journald://"+path))
+	log.Error("user login", "message", "hello", "priority", "high", "syslog_identifier", "other", "code_line", 7)
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := readJournalEntry(t, conn)
+	for name, expect := range map[string]string{
+		"MESSAGE":             "user login",
+		"PRIORITY":            "3",
+		"SYSLOG_IDENTIFIER":   "myapp",
+		"F_MESSAGE":           "hello",
+		"F_PRIORITY":          "high",
+		"F_SYSLOG_IDENTIFIER": "other",
+		"F_CODE_LINE":         "7",
+	} {
+		if fields[name] != expect {
+			t.Errorf("%s: expect %q, got %q", name, expect, fields[name])
+		}
+	}
+	if _, ok := fields["CODE_LINE"]; ok {
+		t.Errorf("expect no CODE_LINE without caller, got %q", fields["CODE_LINE"])
+	}
+}
+
+func TestJournaldRedialBackoff(t *testing.T) {
+	conn, path := listenJournald(t)
+	w, err := newJournaldWriter("journald://" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	conn.Close()
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// the send fails, then the redial
+	if _, err := w.Write([]byte("MESSAGE=first\n")); err == nil {
+		t.Fatal("expect the dial error")
+	}
+	for _, entry := range []string{"MESSAGE=second\n", "MESSAGE=third\n"} {
+		if n, err := w.Write([]byte(entry)); err != nil || n != len(entry) {
+			t.Fatalf("expect the entry to be dropped silently until the next redial, got %d, %v", n, err)
+		}
+	}
+	if w.redial.dropped != 2 || w.redial.interval != minRedialInterval {
+		t.Errorf("expect 2 dropped entries and redial interval %v, got %d and %v", minRedialInterval, w.redial.dropped, w.redial.interval)
+	}
+}
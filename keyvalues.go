@@ -0,0 +1,88 @@
+package lgr
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// BadKey is the key of a value which is not part of a well formed key value pair, like slog uses
+const BadKey = "!BADKEY"
+
+// BadKeyPolicy decides what to do with the malformed key value pairs passed to the logging methods,
+// i.e. a non-string key or a key without value. the malformed pairs are counted in Stats by all policies.
+type BadKeyPolicy int
+
+const (
+	// BadKeyFold logs each malformed value with the BadKey key, like slog does
+	BadKeyFold BadKeyPolicy = iota
+	// BadKeyDrop drops the malformed values, they are only counted in Stats
+	BadKeyDrop
+	// BadKeyPanic panics in development mode, the malformed values are folded like BadKeyFold otherwise
+	BadKeyPanic
+)
+
+var badKeyPolicyNames = [...]string{
+	BadKeyFold:  "fold",
+	BadKeyDrop:  "drop",
+	BadKeyPanic: "panic",
+}
+
+func (p BadKeyPolicy) String() string {
+	if p < BadKeyFold || p > BadKeyPanic {
+		return fmt.Sprintf("BadKeyPolicy(%d)", int(p))
+	}
+	return badKeyPolicyNames[p]
+}
+
+// MarshalText marshals the policy to its name, e.g. "fold"
+func (p BadKeyPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText unmarshals a policy name, e.g. "fold", as used in config files
+func (p *BadKeyPolicy) UnmarshalText(text []byte) error {
+	for policy, name := range badKeyPolicyNames {
+		if string(text) == name {
+			*p = BadKeyPolicy(policy)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown bad key policy %q", text)
+}
+
+// normalizePairs applies the BadKeyPolicy to keysAndValues, which is returned as is if well formed
+func (l *LogImpl) normalizePairs(keysAndValues []interface{}) []interface{} {
+	if checkKeysAndValues(keysAndValues) == nil {
+		return keysAndValues
+	}
+	if l.BadKeyPolicy == BadKeyPanic && l.Development {
+		atomic.AddUint64(&l.counters.malformedPairs, 1)
+		panic(fmt.Sprintf("malformed key value pairs: %v", keysAndValues))
+	}
+
+	normalized := make([]interface{}, 0, len(keysAndValues)+2)
+	var malformed uint64
+	for i := 0; i < len(keysAndValues); {
+		switch keysAndValues[i].(type) {
+		case zap.Field:
+			normalized = append(normalized, keysAndValues[i])
+			i++
+			continue
+		case string:
+			if i+1 < len(keysAndValues) {
+				normalized = append(normalized, keysAndValues[i], keysAndValues[i+1])
+				i += 2
+				continue
+			}
+		}
+		malformed++
+		if l.BadKeyPolicy != BadKeyDrop {
+			normalized = append(normalized, BadKey, keysAndValues[i])
+		}
+		i++
+	}
+	atomic.AddUint64(&l.counters.malformedPairs, malformed)
+	return normalized
+}
@@ -0,0 +1,64 @@
+package lgr
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestBadKeyFold(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithTimeKey(""), WithDisableCaller(true))
+
+	log.Info("well formed", "uid", 7, zap.Bool("admin", false))
+	log.Info("odd", "uid", 7, "dangling")
+	log.With(42, "answer").InfoCtx(context.Background(), "non-string key")
+
+	expect := `{"level":"info","msg":"well formed","uid":7,"admin":false}
+{"level":"info","msg":"odd","uid":7,"!BADKEY":"dangling"}
+{"level":"info","msg":"non-string key","!BADKEY":42,"!BADKEY":"answer"}`
+	if got := strings.TrimSpace(buf.String()); got != expect {
+		t.Errorf("expect\n%s\ngot\n%s", expect, got)
+	}
+	if n := log.Stats().MalformedPairs; n != 3 {
+		t.Errorf("expect 3 malformed pairs, got %d", n)
+	}
+}
+
+func TestBadKeyDrop(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithTimeKey(""), WithDisableCaller(true), WithBadKeyPolicy(BadKeyDrop))
+
+	log.Warn("odd", "uid", 7, "dangling")
+	if got := strings.TrimSpace(buf.String()); got != `{"level":"warn","msg":"odd","uid":7}` {
+		t.Errorf("unexpected output %s", got)
+	}
+	if n := log.Named("sub").Stats().MalformedPairs; n != 1 {
+		t.Errorf("expect 1 malformed pair, got %d", n)
+	}
+}
+
+func TestBadKeyPanic(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	prod := NewLogger(WithCustomSink(buf), WithTimeKey(""), WithDisableCaller(true), WithBadKeyPolicy(BadKeyPanic))
+	if r := recovered(func() { prod.Error("odd", "dangling") }); r != nil {
+		t.Errorf("expect no panic in production, got %v", r)
+	}
+	if got := strings.TrimSpace(buf.String()); got != `{"level":"error","msg":"odd","!BADKEY":"dangling"}` {
+		t.Errorf("unexpected output %s", got)
+	}
+
+	dev := NewLogger(WithCustomSink(buf), WithDevelopment(true), WithBadKeyPolicy(BadKeyPanic))
+	if r := recovered(func() { dev.Info("odd", "dangling") }); r == nil {
+		t.Errorf("expect panic in development")
+	}
+}
+
+func TestInvalidBadKeyPolicy(t *testing.T) {
+	if _, err := NewLoggerE(WithBadKeyPolicy(BadKeyPolicy(9))); err == nil {
+		t.Errorf("expect error for unknown bad key policy")
+	}
+}
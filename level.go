@@ -0,0 +1,200 @@
+package lgr
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// noOverride is stored in levelRegistry.minOverride when no per name level is set
+const noOverride = math.MaxInt32
+
+// levelRegistry holds the root level and the per logger name level overrides.
+// it is shared by all loggers derived from the same root via Named and With.
+type levelRegistry struct {
+	root zap.AtomicLevel
+
+	mu    sync.RWMutex
+	names map[string]zapcore.Level
+	// minOverride caches the lowest level in names, so Enabled does not need the lock
+	minOverride int32
+}
+
+func newLevelRegistry(root zapcore.Level) *levelRegistry {
+	return &levelRegistry{
+		root:        zap.NewAtomicLevelAt(root),
+		names:       map[string]zapcore.Level{},
+		minOverride: noOverride,
+	}
+}
+
+// Enabled reports whether lvl may be enabled for any logger name,
+// the precise per name check is done by levelCore.Check
+func (r *levelRegistry) Enabled(lvl zapcore.Level) bool {
+	return r.root.Enabled(lvl) || int32(lvl) >= atomic.LoadInt32(&r.minOverride)
+}
+
+// levelFor returns the effective level for the dotted logger name.
+// the longest matching name prefix wins, e.g. rule "db" applies to "db.conn" as well.
+func (r *levelRegistry) levelFor(name string) zapcore.Level {
+	if atomic.LoadInt32(&r.minOverride) == noOverride {
+		return r.root.Level()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for name != "" {
+		if lvl, ok := r.names[name]; ok {
+			return lvl
+		}
+		idx := strings.LastIndexByte(name, '.')
+		if idx < 0 {
+			break
+		}
+		name = name[:idx]
+	}
+	return r.root.Level()
+}
+
+// overrides returns a copy of the per name levels
+func (r *levelRegistry) overrides() map[string]zapcore.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m := make(map[string]zapcore.Level, len(r.names))
+	for name, lvl := range r.names {
+		m[name] = lvl
+	}
+	return m
+}
+
+func (r *levelRegistry) setNamed(name string, lvl zapcore.Level) {
+	r.mu.Lock()
+	r.names[name] = lvl
+	r.updateMinLocked()
+	r.mu.Unlock()
+}
+
+func (r *levelRegistry) unsetNamed(name string) {
+	r.mu.Lock()
+	delete(r.names, name)
+	r.updateMinLocked()
+	r.mu.Unlock()
+}
+
+// replaceNamed replaces all per name levels at once
+func (r *levelRegistry) replaceNamed(names map[string]zapcore.Level) {
+	r.mu.Lock()
+	r.names = names
+	r.updateMinLocked()
+	r.mu.Unlock()
+}
+
+func (r *levelRegistry) updateMinLocked() {
+	lowest := int32(noOverride)
+	for _, lvl := range r.names {
+		if int32(lvl) < lowest {
+			lowest = int32(lvl)
+		}
+	}
+	atomic.StoreInt32(&r.minOverride, lowest)
+}
+
+// parseLevelRules parses per logger name level rules like "db=debug,http.client=warn,*=info".
+// the wildcard name "*" sets the level for every logger without a more specific rule,
+// it is returned as root, which is nil if the rules have no wildcard.
+func parseLevelRules(spec string) (root *zapcore.Level, names map[string]zapcore.Level, err error) {
+	names = map[string]zapcore.Level{}
+	for _, rule := range strings.Split(spec, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		idx := strings.IndexByte(rule, '=')
+		if idx <= 0 {
+			return nil, nil, fmt.Errorf("invalid level rule %q, must be in name=level format", rule)
+		}
+		name, level := strings.TrimSpace(rule[:idx]), strings.TrimSpace(rule[idx+1:])
+		lvl, err := ParseLevel(level)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid level rule %q: %w", rule, err)
+		}
+		if name == "*" {
+			root = &lvl
+			continue
+		}
+		names[name] = lvl
+	}
+	return root, names, nil
+}
+
+// levelCore filters entries by the effective level of their logger name
+type levelCore struct {
+	zapcore.Core
+	levels *levelRegistry
+}
+
+func newLevelCore(core zapcore.Core, levels *levelRegistry) zapcore.Core {
+	return &levelCore{Core: core, levels: levels}
+}
+
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return c.levels.Enabled(lvl)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < c.levels.levelFor(ent.LoggerName) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// SetNamedLevel overrides the level for the logger with the given dotted name
+// and all its descendants, e.g. "db" also applies to "db.conn".
+// the name is the full name, including the one set by WithName.
+// an unknown level name leaves the level unchanged, use SetNamedLevelE to get the error.
+func (l *LogImpl) SetNamedLevel(name, level string) {
+	_ = l.SetNamedLevelE(name, level)
+}
+
+// SetNamedLevelE is like SetNamedLevel, but it reports an unknown level name, see ParseLevel
+func (l *LogImpl) SetNamedLevelE(name, level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.levels.setNamed(name, lvl)
+	return nil
+}
+
+// UnsetNamedLevel removes the level override for the given logger name
+func (l *LogImpl) UnsetNamedLevel(name string) {
+	l.levels.unsetNamed(name)
+}
+
+// SetLevels replaces all per logger name levels with the given rules,
+// e.g. "db=debug,http.client=warn,*=info", see WithLevels for the format.
+func (l *LogImpl) SetLevels(spec string) error {
+	root, names, err := parseLevelRules(spec)
+	if err != nil {
+		return err
+	}
+	if root != nil {
+		l.levels.root.SetLevel(*root)
+	}
+	l.levels.replaceNamed(names)
+	return nil
+}
+
+// NamedLevel returns the effective level for the given logger name
+func (l *LogImpl) NamedLevel(name string) string {
+	return levelName(l.levels.levelFor(name))
+}
@@ -0,0 +1,115 @@
+package lgr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+type levelHandler struct {
+	levels *levelRegistry
+}
+
+type levelPayload struct {
+	Name   string            `json:"name,omitempty"`
+	Level  string            `json:"level"`
+	Levels map[string]string `json:"levels,omitempty"`
+}
+
+type errorPayload struct {
+	Error string `json:"error"`
+}
+
+// LevelHandler returns an http.Handler to query and change the level of the logger tree at runtime.
+//
+// GET returns the current level as JSON, e.g. {"level":"info","levels":{"db":"debug"}}.
+// PUT and POST change the level, the new level can be sent as JSON {"level":"debug"}
+// or form encoded level=debug.
+// DELETE removes the level override for the named logger.
+//
+// an optional name (the "name" query parameter or the "name" body field)
+// targets the named logger and its descendants only, e.g. PUT /log/level?name=db level=debug
+func (l *LogImpl) LevelHandler() http.Handler {
+	return &levelHandler{levels: l.levels}
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	enc := json.NewEncoder(w)
+	w.Header().Set("Content-Type", "application/json")
+
+	name := r.URL.Query().Get("name")
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		req, err := decodeLevelRequest(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = enc.Encode(errorPayload{Error: err.Error()})
+			return
+		}
+		if req.Name != "" {
+			name = req.Name
+		}
+		lvl, err := ParseLevel(req.Level)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = enc.Encode(errorPayload{Error: err.Error()})
+			return
+		}
+		if name == "" {
+			h.levels.root.SetLevel(lvl)
+		} else {
+			h.levels.setNamed(name, lvl)
+		}
+	case http.MethodDelete:
+		if name == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = enc.Encode(errorPayload{Error: "name is required to delete a level override"})
+			return
+		}
+		h.levels.unsetNamed(name)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_ = enc.Encode(errorPayload{Error: fmt.Sprintf("method %s not allowed, only GET, PUT, POST and DELETE are supported", r.Method)})
+		return
+	}
+
+	_ = enc.Encode(h.current(name))
+}
+
+func (h *levelHandler) current(name string) levelPayload {
+	if name != "" {
+		return levelPayload{Name: name, Level: levelName(h.levels.levelFor(name))}
+	}
+
+	resp := levelPayload{Level: levelName(h.levels.root.Level())}
+	if overrides := h.levels.overrides(); len(overrides) > 0 {
+		resp.Levels = make(map[string]string, len(overrides))
+		for n, lvl := range overrides {
+			resp.Levels[n] = levelName(lvl)
+		}
+	}
+	return resp
+}
+
+func decodeLevelRequest(r *http.Request) (levelPayload, error) {
+	var req levelPayload
+	// the media type may have parameters, e.g. charset=UTF-8
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" {
+		if err := r.ParseForm(); err != nil {
+			return req, err
+		}
+		req.Name = r.PostForm.Get("name")
+		req.Level = r.PostForm.Get("level")
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return req, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	if req.Level == "" {
+		return req, errors.New("must specify logging level")
+	}
+	return req, nil
+}
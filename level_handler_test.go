@@ -0,0 +1,71 @@
+package lgr
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestLevelHandler(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithEncoding("console"), WithCustomSink(buf), WithTimeKey(""), WithColorLevel(false), WithDisableCaller(true))
+	db := log.Named("db")
+	srv := httptest.NewServer(log.LevelHandler())
+	defer srv.Close()
+
+	do := func(method, path, contentType, body string) (int, string) {
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		out := new(bytes.Buffer)
+		_, _ = out.ReadFrom(resp.Body)
+		return resp.StatusCode, strings.TrimSpace(out.String())
+	}
+
+	tests := []struct {
+		method, path, contentType, body string
+		wantCode                        int
+		wantBody                        string
+	}{
+		{http.MethodGet, "", "", "", http.StatusOK, `{"level":"info"}`},
+		{http.MethodPut, "", "application/json", `{"level":"warn"}`, http.StatusOK, `{"level":"warn"}`},
+		{http.MethodPost, "?name=db", "application/x-www-form-urlencoded", url.Values{"level": {"debug"}}.Encode(), http.StatusOK, `{"name":"db","level":"debug"}`},
+		{http.MethodPost, "?name=db", "application/x-www-form-urlencoded; charset=UTF-8", url.Values{"level": {"debug"}}.Encode(), http.StatusOK, `{"name":"db","level":"debug"}`},
+		{http.MethodGet, "", "", "", http.StatusOK, `{"level":"warn","levels":{"db":"debug"}}`},
+		{http.MethodGet, "?name=db.conn", "", "", http.StatusOK, `{"name":"db.conn","level":"debug"}`},
+		{http.MethodPut, "", "application/json", `{"level":"bogus"}`, http.StatusBadRequest, `{"error":"unrecognized level: \"bogus\""}`},
+		{http.MethodPut, "", "application/json", `{}`, http.StatusBadRequest, `{"error":"must specify logging level"}`},
+		{http.MethodPatch, "", "", "", http.StatusMethodNotAllowed, `{"error":"method PATCH not allowed, only GET, PUT, POST and DELETE are supported"}`},
+	}
+	for _, tt := range tests {
+		code, body := do(tt.method, tt.path, tt.contentType, tt.body)
+		if code != tt.wantCode || body != tt.wantBody {
+			t.Errorf("%s %s: got %d %s, want %d %s", tt.method, tt.path, code, body, tt.wantCode, tt.wantBody)
+		}
+	}
+
+	log.Info("root info is filtered")
+	db.Debug("db debug is visible")
+	if got := buf.String(); got != "debug\tdb\tdb debug is visible\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+
+	if code, body := do(http.MethodDelete, "?name=db", "", ""); code != http.StatusOK || body != `{"name":"db","level":"warn"}` {
+		t.Errorf("DELETE: got %d %s", code, body)
+	}
+	if db.NamedLevel("db") != "warn" {
+		t.Errorf("expect db level to fall back to root level warn, got %s", db.NamedLevel("db"))
+	}
+}
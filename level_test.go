@@ -0,0 +1,190 @@
+package lgr
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSetLevelSharedByDerivedLoggers(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithEncoding("console"), WithCustomSink(buf), WithTimeKey(""), WithColorLevel(false), WithDisableCaller(true))
+	child := log.Named("sub").With("foo", "bar")
+
+	child.Debug("invisible")
+	if buf.Len() != 0 {
+		t.Fatalf("debug log should be disabled at info level, got %q", buf.String())
+	}
+
+	log.SetLevel("debug")
+	if child.Level() != "debug" {
+		t.Fatalf("expect child level debug, got %s", child.Level())
+	}
+	child.Debug("visible")
+	if !strings.Contains(buf.String(), "visible") {
+		t.Fatalf("debug log should be enabled after SetLevel, got %q", buf.String())
+	}
+
+	buf.Reset()
+	child.SetLevel("error")
+	log.Warn("invisible")
+	if buf.Len() != 0 {
+		t.Fatalf("warn log should be disabled at error level, got %q", buf.String())
+	}
+}
+
+func TestLevelRules(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithName("app"), WithLevels("app.db=debug, app.http.client=warn, *=info"),
+		WithEncoding("console"), WithCustomSink(buf), WithTimeKey(""), WithColorLevel(false), WithDisableCaller(true))
+
+	log.Debug("app debug")
+	log.Info("app info")
+	log.Named("db").Named("conn").Debug("conn debug")
+	log.Named("http").Info("http info")
+	log.Named("http").Named("client").Info("client info")
+	log.Named("http").Named("client").Warn("client warn")
+
+	expect := `info	app	app info
+debug	app.db.conn	conn debug
+info	app.http	http info
+warn	app.http.client	client warn
+`
+	if buf.String() != expect {
+		t.Errorf("unexpected output:\n%s\nexpect:\n%s", buf.String(), expect)
+	}
+
+	if err := log.SetLevels("*=error"); err != nil {
+		t.Fatal(err)
+	}
+	if got := log.NamedLevel("app.db"); got != "error" {
+		t.Errorf("expect rules replaced, got app.db level %s", got)
+	}
+}
+
+func TestParseLevelRules(t *testing.T) {
+	root, names, err := parseLevelRules("db=debug,*=warn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root == nil || root.String() != "warn" || len(names) != 1 || names["db"].String() != "debug" {
+		t.Errorf("unexpected result root=%v names=%v", root, names)
+	}
+
+	for _, spec := range []string{"db", "=debug", "db=verbose"} {
+		if _, _, err := parseLevelRules(spec); err == nil {
+			t.Errorf("expect error for %q", spec)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	cases := map[string]zapcore.Level{
+		"trace":   TraceLevel,
+		"DEBUG":   zapcore.DebugLevel,
+		" Info ":  zapcore.InfoLevel,
+		"warning": zapcore.WarnLevel,
+		"WARN":    zapcore.WarnLevel,
+		"err":     zapcore.ErrorLevel,
+		"dpanic":  zapcore.DPanicLevel,
+		"Panic":   zapcore.PanicLevel,
+		"fatal":   zapcore.FatalLevel,
+	}
+	for text, expect := range cases {
+		lvl, err := ParseLevel(text)
+		if err != nil || lvl != expect {
+			t.Errorf("ParseLevel(%q) = %v, %v, expect %v", text, lvl, err, expect)
+		}
+	}
+
+	for _, junk := range []string{"", "verbose", "infoo"} {
+		if _, err := ParseLevel(junk); err == nil {
+			t.Errorf("expect error for level %q", junk)
+		}
+	}
+}
+
+func TestInvalidLevel(t *testing.T) {
+	_, err := NewLoggerE(WithLevel("verbose"))
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Field != "Level" {
+		t.Fatalf("expect Level field error, got %v", err)
+	}
+}
+
+func TestTraceLevel(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithTimeKey(""), WithLevel("debug"))
+
+	log.Trace("invisible")
+	if buf.Len() != 0 {
+		t.Fatalf("trace log should be disabled at debug level, got %q", buf.String())
+	}
+
+	log.SetLevel("TRACE")
+	if log.Level() != "trace" {
+		t.Fatalf("expect level trace, got %s", log.Level())
+	}
+	log.Trace("frame received", "len", 42, zap.String("peer", "10.0.0.1"))
+	got := strings.TrimSpace(buf.String())
+	if !strings.HasPrefix(got, `{"level":"trace","caller":"lgr/level_test.go:`) ||
+		!strings.HasSuffix(got, `"msg":"frame received","len":42,"peer":"10.0.0.1"}`) {
+		t.Errorf("unexpected output %s", got)
+	}
+}
+
+func TestTraceLevelEncoders(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithEncoding("console"), WithCustomSink(buf), WithTimeKey(""), WithDisableCaller(true), WithLevel("trace"))
+	log.Trace("frame received")
+	if got := buf.String(); got != "\x1b[36mTRACE\x1b[0m\tframe received\n" {
+		t.Errorf("unexpected console output %q", got)
+	}
+
+	buf.Reset()
+	log = NewLogger(WithEncoding("cli"), WithCustomSink(buf), WithTimeKey(""), WithDisableCaller(true), WithLevel("trace"))
+	log.Trace("frame received", "len", 42)
+	if got := buf.String(); !strings.Contains(got, "·") || !strings.Contains(got, "frame received") {
+		t.Errorf("unexpected cli output %q", got)
+	}
+}
+
+func TestSetLevelRejectsUnknownLevel(t *testing.T) {
+	log := NewLogger(WithCustomSink(bytes.NewBuffer(nil)), WithLevel("warn"))
+	if err := log.SetLevelE("degub"); err == nil || err.Error() != `unrecognized level: "degub"` {
+		t.Errorf("expect unrecognized level error, got %v", err)
+	}
+	if log.Level() != "warn" {
+		t.Errorf("expect level unchanged, got %s", log.Level())
+	}
+	log.SetLevel("degub")
+	if log.Level() != "warn" {
+		t.Errorf("expect SetLevel to leave the level unchanged, got %s", log.Level())
+	}
+
+	if err := log.SetNamedLevelE("db", "degub"); err == nil {
+		t.Error("expect unrecognized level error for named level")
+	}
+	if log.NamedLevel("db") != "warn" {
+		t.Errorf("expect named level unchanged, got %s", log.NamedLevel("db"))
+	}
+	if err := log.SetNamedLevelE("db", "debug"); err != nil || log.NamedLevel("db") != "debug" {
+		t.Errorf("expect named level debug, got %s, %v", log.NamedLevel("db"), err)
+	}
+}
+
+func TestCliTablesKeepIndexing(t *testing.T) {
+	if len(Colors) != 7 || len(Strings) != 7 {
+		t.Fatalf("expect 7 entries indexed by level+1, got %d colors and %d strings", len(Colors), len(Strings))
+	}
+	if Strings[zapcore.DebugLevel+1] != "•" || Strings[zapcore.ErrorLevel+1] != "⨯" {
+		t.Errorf("unexpected strings %q", Strings)
+	}
+	if levelString(TraceLevel) != "·" || levelColor(TraceLevel) != TraceColor {
+		t.Error("expect TraceLevel held apart from the tables")
+	}
+}
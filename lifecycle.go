@@ -0,0 +1,62 @@
+package lgr
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"syscall"
+
+	"go.uber.org/multierr"
+)
+
+// closer releases the resources owned by a logger tree, exactly once
+type closer struct {
+	once sync.Once
+	err  error
+
+	stopSignal func()
+	stopAsync  []func(ctx context.Context) error
+	closeSinks []func()
+}
+
+// Close flushes the pending entries, stops the background workers and closes the sinks opened by the logger.
+// it is shared by all loggers derived via Named and With, only the first call does the work,
+// the later calls return the same error.
+// ctx bounds the time waiting for the async writer to write the queued entries, the entries left when ctx is done are dropped.
+// custom sinks set by WithCustomSink and WithCustomErrorSink are not closed, they are owned by the caller.
+func (l *LogImpl) Close(ctx context.Context) error {
+	l.closer.once.Do(func() {
+		l.closer.err = l.close(ctx)
+	})
+	return l.closer.err
+}
+
+func (l *LogImpl) close(ctx context.Context) error {
+	c := l.closer
+	if c.stopSignal != nil {
+		c.stopSignal()
+	}
+
+	// the async writers have exited when stopAsync returns, even if ctx is done, so the sinks are not closed under them
+	var err error
+	for _, stopAsync := range c.stopAsync {
+		err = multierr.Append(err, stopAsync(ctx))
+	}
+	if syncErr := l.s.Sync(); syncErr != nil && !isIgnorableSyncError(syncErr) {
+		err = multierr.Append(err, syncErr)
+	}
+	for _, closeSink := range c.closeSinks {
+		closeSink()
+	}
+	return err
+}
+
+// isIgnorableSyncError reports whether the error is caused by syncing a terminal or a pipe, e.g. stderr
+func isIgnorableSyncError(err error) bool {
+	for _, e := range multierr.Errors(err) {
+		if !errors.Is(e, syscall.EINVAL) && !errors.Is(e, syscall.ENOTTY) && !errors.Is(e, syscall.EBADF) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package lgr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	log := NewLogger(WithOutputPaths(path), WithErrorOutputPaths(filepath.Join(dir, "err.log")),
+		WithReopenOnSignal(true), WithAsync(16, time.Hour, OverflowBlock))
+	child := log.Named("child").With("foo", "bar")
+	for i := 0; i < 5; i++ {
+		child.Info("before close", "i", i)
+	}
+
+	if err := child.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatalf("expect the second Close to return the result of the first one, got %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(content), "before close"); n != 5 {
+		t.Errorf("expect the queued entries written before close, got %d", n)
+	}
+	for _, f := range log.reopenFiles {
+		if _, err := f.file.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("expect %s closed, got %v", f.path, err)
+		}
+	}
+}
+
+func TestCloseTimeout(t *testing.T) {
+	w := newBlockingWriter()
+	log := NewLogger(WithCustomSink(w), WithAsync(16, 0, OverflowBlock))
+
+	log.Info("blocked")
+	<-w.started
+	log.Info("queued")
+	// Close waits for the blocked write, then abandons the queued entry
+	time.AfterFunc(50*time.Millisecond, func() { close(w.unblock) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := log.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect deadline exceeded, got %v", err)
+	}
+	written := strings.Count(w.String(), "queued")
+	if dropped := log.Stats().AsyncDropped; written+int(dropped) != 1 {
+		t.Errorf("expect the queued entry written or dropped, got %d written and %d dropped", written, dropped)
+	}
+}
+
+func TestCloseExpiredContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	log := NewLogger(WithOutputPaths(path), WithReopenOnSignal(true), WithAsync(1024, time.Hour, OverflowBlock),
+		WithTimeKey(""), WithDisableCaller(true))
+	for i := 0; i < 100; i++ {
+		log.Info("before close", "i", i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := log.Close(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("expect nil or canceled, got %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := strings.Count(string(content), "before close")
+	if dropped := log.Stats().AsyncDropped; written+int(dropped) != 100 {
+		t.Errorf("expect each entry written or dropped, got %d written and %d dropped", written, dropped)
+	}
+	if len(log.reopenFiles) == 0 {
+		t.Fatal("expect the file output to be tracked")
+	}
+	for _, f := range log.reopenFiles {
+		if _, err := f.file.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("expect %s closed, got %v", f.path, err)
+		}
+	}
+}
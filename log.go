@@ -1,9 +1,11 @@
 package lgr
 
 import (
+	"context"
+	"errors"
 	"io"
-	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
@@ -28,30 +30,62 @@ var (
 var _ Logger = (*LogImpl)(nil)
 
 type LogImpl struct {
-	s *zap.SugaredLogger
+	s        *zap.SugaredLogger
+	z        *zap.Logger    // the desugared s, used by the typed field methods
+	name     string         // the full dotted logger name, which zap does not expose
+	levels   *levelRegistry // shared by all loggers derived via Named and With
+	counters *counters      // shared by all loggers derived via Named and With
+
+	reopenFiles []*reopenFile
+	closer      *closer       // shared by all loggers derived via Named and With
+	cores       *coreHolder   // shared by all loggers derived via Named and With
+	optionErrs  []*FieldError // reported by build, e.g. an invalid environment variable of WithEnv
 	Config
 }
 
 type Config struct {
-	DisableStacktrace bool
-	DisableCaller     bool
-	Development       bool
-	Sampling          bool
-	ColorLevel        bool // this is only for console encoding
-	CliLevel          bool
-	Name              string // Named adds a sub-scope to the logger's name. See Logger.Named for details.
-	Encoding          string
-	Level             string
-	TimeKey           string
-	DatetimeLayout    string
-	InitialFields     []string // InitialFields is a collection of key,value paris to add to the root logger
-	OutputPaths       []string
-	ErrorOutputPaths  []string  // for zap logging self error
-	CustomSink        io.Writer // this will override OutputPaths config
+	DisableStacktrace  bool               `json:"disable_stacktrace" yaml:"disable_stacktrace"`
+	DisableCaller      bool               `json:"disable_caller" yaml:"disable_caller"`
+	Development        bool               `json:"development" yaml:"development"`
+	Sampling           bool               `json:"sampling" yaml:"sampling"`                       // enable sampling, see WithSampling
+	SamplingTick       time.Duration      `json:"sampling_tick" yaml:"sampling_tick"`             // sampling interval, default to one second
+	SamplingInitial    int                `json:"sampling_initial" yaml:"sampling_initial"`       // log the first N entries with the same level and message in each tick
+	SamplingThereafter int                `json:"sampling_thereafter" yaml:"sampling_thereafter"` // then log every Mth entry in the same tick
+	ColorLevel         bool               `json:"color_level" yaml:"color_level"`                 // this is only for console encoding
+	CliLevel           bool               `json:"cli_level" yaml:"cli_level"`
+	Name               string             `json:"name" yaml:"name"` // Named adds a sub-scope to the logger's name. See Logger.Named for details.
+	Encoding           string             `json:"encoding" yaml:"encoding"`
+	Level              string             `json:"level" yaml:"level"`
+	Levels             string             `json:"levels" yaml:"levels"` // per logger name level rules, e.g. "db=debug,http.client=warn,*=info"
+	TimeKey            string             `json:"time_key" yaml:"time_key"`
+	DatetimeLayout     string             `json:"datetime_layout" yaml:"datetime_layout"`
+	InitialFields      []interface{}      `json:"initial_fields" yaml:"initial_fields"`             // InitialFields is a collection of key,value pairs or zap fields to add to the root logger
+	OutputPaths        []string           `json:"output_paths" yaml:"output_paths"`                 // default to stderr if there are no Outputs and CustomSinks
+	ErrorOutputPaths   []string           `json:"error_output_paths" yaml:"error_output_paths"`     // for zap logging self error
+	Outputs            []OutputConfig     `json:"outputs" yaml:"outputs"`                           // outputs with their own encoding and level, in addition to OutputPaths
+	CustomSink         io.Writer          `json:"-" yaml:"-"`                                       // Deprecated: use CustomSinks, it is written in addition to OutputPaths like them
+	CustomSinks        []io.Writer        `json:"-" yaml:"-"`                                       // in addition to OutputPaths and Outputs, not closed by Close
+	CustomErrorSink    io.Writer          `json:"-" yaml:"-"`                                       // used instead of ErrorOutputPaths if set
+	Rotation           *RotateConfig      `json:"rotation" yaml:"rotation"`                         // rotate the file output paths, see WithRotation
+	ReopenOnSignal     bool               `json:"reopen_on_signal" yaml:"reopen_on_signal"`         // reopen the file output paths on SIGHUP, see WithReopenOnSignal
+	Async              bool               `json:"async" yaml:"async"`                               // write entries in a background goroutine, see WithAsync
+	AsyncBufferSize    int                `json:"async_buffer_size" yaml:"async_buffer_size"`       // max number of queued entries
+	AsyncFlushInterval time.Duration      `json:"async_flush_interval" yaml:"async_flush_interval"` // interval to write the buffered entries, zero means write each entry at once
+	AsyncOverflow      OverflowPolicy     `json:"async_overflow" yaml:"async_overflow"`
+	ContextExtractors  []ContextExtractor `json:"-" yaml:"-"`                           // extract fields from the context of the *Ctx methods
+	TraceKeys          TraceKeys          `json:"trace_keys" yaml:"trace_keys"`         // names of the trace correlation fields, see WithTraceKeys
+	SpanEvents         bool               `json:"span_events" yaml:"span_events"`       // mirror the ErrorCtx entries as span events, see WithSpanEvents
+	BadKeyPolicy       BadKeyPolicy       `json:"bad_key_policy" yaml:"bad_key_policy"` // what to do with malformed key value pairs, see WithBadKeyPolicy
+
+	// SamplingHook is called on each sampling decision, see WithSamplingHook
+	SamplingHook func(entry zapcore.Entry, dec zapcore.SamplingDecision) `json:"-" yaml:"-"`
 }
 
 func init() {
 	zap.RegisterEncoder("cli", CliEncoding)
+	if err := zap.RegisterSink(RotateScheme, newRotateSink); err != nil {
+		panic(err)
+	}
 }
 
 func CliEncoding(config zapcore.EncoderConfig) (zapcore.Encoder, error) {
@@ -59,11 +93,22 @@ func CliEncoding(config zapcore.EncoderConfig) (zapcore.Encoder, error) {
 }
 
 func NewDefault() *LogImpl {
-	l := defaultCfg()
-	return l.build()
+	return NewLogger()
 }
 
+// NewLogger is like NewLoggerE, but panics if the options are invalid
 func NewLogger(options ...Option) *LogImpl {
+	l, err := NewLoggerE(options...)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// NewLoggerE creates a logger with the options applied to the default config.
+// all the invalid options are reported at once by a *ConfigError,
+// an error opening the output paths is reported as a *ConfigError too.
+func NewLoggerE(options ...Option) (*LogImpl, error) {
 	l := defaultCfg()
 	// apply otions
 
@@ -95,11 +140,34 @@ func S() *LogImpl {
 }
 
 type Logger interface {
+	Trace(msg string, keysAndValues ...interface{})
 	Debug(msg string, keysAndValues ...interface{})
 	Info(msg string, keysAndValues ...interface{})
 	Warn(msg string, keysAndValues ...interface{})
 	Error(msg string, keysAndValues ...interface{})
+	DPanic(msg string, keysAndValues ...interface{})
+	Panic(msg string, keysAndValues ...interface{})
 	Fatal(msg string, keysAndValues ...interface{})
+	DebugCtx(ctx context.Context, msg string, keysAndValues ...interface{})
+	InfoCtx(ctx context.Context, msg string, keysAndValues ...interface{})
+	WarnCtx(ctx context.Context, msg string, keysAndValues ...interface{})
+	ErrorCtx(ctx context.Context, msg string, keysAndValues ...interface{})
+	Debugf(template string, args ...interface{})
+	Infof(template string, args ...interface{})
+	Warnf(template string, args ...interface{})
+	Errorf(template string, args ...interface{})
+	Fatalf(template string, args ...interface{})
+	Debugln(args ...interface{})
+	Infoln(args ...interface{})
+	Warnln(args ...interface{})
+	Errorln(args ...interface{})
+	Fatalln(args ...interface{})
+	TraceF(msg string, fields ...Field)
+	DebugF(msg string, fields ...Field)
+	InfoF(msg string, fields ...Field)
+	WarnF(msg string, fields ...Field)
+	ErrorF(msg string, fields ...Field)
+	FatalF(msg string, fields ...Field)
 	Sync() error
 	Named(name string) *LogImpl
 	With(keysAndValues ...interface{}) *LogImpl
@@ -107,20 +175,35 @@ type Logger interface {
 
 func defaultCfg() *LogImpl {
 	c := Config{
-		DisableStacktrace: true,
-		DisableCaller:     false,
-		Development:       false,
-		Sampling:          false,
-		ColorLevel:        true,
-		Name:              "",
-		Encoding:          EncodingJSON,
-		Level:             "info",
-		TimeKey:           "ts",
-		DatetimeLayout:    DefaultTimeLayout,
-		InitialFields:     []string{},
-		OutputPaths:       []string{"stderr"},
-		ErrorOutputPaths:  []string{"stderr"},
-		CustomSink:        nil,
+		DisableStacktrace:  true,
+		DisableCaller:      false,
+		Development:        false,
+		Sampling:           false,
+		SamplingTick:       time.Second,
+		SamplingInitial:    100,
+		SamplingThereafter: 100,
+		ColorLevel:         true,
+		Name:               "",
+		Encoding:           EncodingJSON,
+		Level:              "info",
+		Levels:             "",
+		TimeKey:            "ts",
+		DatetimeLayout:     DefaultTimeLayout,
+		InitialFields:      []interface{}{},
+		OutputPaths:        nil,
+		ErrorOutputPaths:   []string{"stderr"},
+		CustomSink:         nil,
+		CustomSinks:        nil,
+		CustomErrorSink:    nil,
+		Rotation:           nil,
+		ReopenOnSignal:     false,
+		Async:              false,
+		AsyncBufferSize:    4096,
+		AsyncFlushInterval: time.Second,
+		AsyncOverflow:      OverflowDropNewest,
+		TraceKeys:          OTelTraceKeys,
+		SpanEvents:         false,
+		BadKeyPolicy:       BadKeyFold,
 	}
 
 	l := &LogImpl{Config: c}
@@ -129,8 +212,16 @@ func defaultCfg() *LogImpl {
 
 func (l *LogImpl) clone() *LogImpl {
 	cloned := &LogImpl{
-		s:      l.s,
-		Config: l.Config,
+		s:        l.s,
+		z:        l.z,
+		name:     l.name,
+		levels:   l.levels,
+		counters: l.counters,
+
+		reopenFiles: l.reopenFiles,
+		closer:      l.closer,
+		cores:       l.cores,
+		Config:      l.Config,
 	}
 	return cloned
 }
@@ -143,20 +234,7 @@ func ReplaceGlobal(newlgr *LogImpl) *LogImpl {
 	return _globalLog
 }
 
-func (cfg *Config) openSinks() (zapcore.WriteSyncer, zapcore.WriteSyncer, error) {
-	sink, closeOut, err := zap.Open(cfg.OutputPaths...)
-	if err != nil {
-		return nil, nil, err
-	}
-	errSink, _, err := zap.Open(cfg.ErrorOutputPaths...)
-	if err != nil {
-		closeOut()
-		return nil, nil, err
-	}
-	return sink, errSink, nil
-}
-
-func (cfg *Config) buildOptions(errSink zapcore.WriteSyncer, scfg *zap.SamplingConfig) []zap.Option {
+func (cfg *Config) buildOptions(errSink zapcore.WriteSyncer) []zap.Option {
 	opts := []zap.Option{zap.ErrorOutput(errSink)}
 
 	if cfg.Development {
@@ -167,64 +245,94 @@ func (cfg *Config) buildOptions(errSink zapcore.WriteSyncer, scfg *zap.SamplingC
 		opts = append(opts, zap.AddCaller())
 	}
 
-	stackLevel := zap.ErrorLevel
-	if cfg.Development {
-		stackLevel = zap.WarnLevel
-	}
-	if !cfg.DisableStacktrace {
+	if stackLevel := cfg.stackLevel(); stackLevel != nil {
 		opts = append(opts, zap.AddStacktrace(stackLevel))
 	}
 
-	if scfg != nil {
-		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
-			return zapcore.NewSamplerWithOptions(
-				core,
-				time.Second,
-				scfg.Initial,
-				scfg.Thereafter,
-			)
-		}))
-	}
-
 	return opts
 }
 
-func (l *LogImpl) build() *LogImpl {
-	if l.Encoding != EncodingConsole && l.Encoding != EncodingJSON && l.Encoding != EncodingCli {
-		panic("invalid encoding config")
+// stackLevel returns the level from which stacktraces are added, nil if they are disabled
+func (cfg *Config) stackLevel() zapcore.LevelEnabler {
+	if cfg.DisableStacktrace {
+		return nil
 	}
+	if cfg.Development {
+		return zap.WarnLevel
+	}
+	return zap.ErrorLevel
+}
 
-	level := zap.NewAtomicLevelAt(zap.InfoLevel)
-	if l.Level != "" {
-		level = zap.NewAtomicLevelAt(getZapLevel(l.Level))
+// newCore builds the core writing to sink with the encoder and sampling settings,
+// it is rebuilt on config reload, see WatchConfig.
+func (cfg *Config) newCore(outs []output, levels *levelRegistry, counters *counters) zapcore.Core {
+	cores := make([]zapcore.Core, 0, len(outs))
+	for _, out := range outs {
+		encoding := out.encoding
+		if encoding == "" {
+			encoding = cfg.Encoding
+		}
+		var enabler zapcore.LevelEnabler = levels
+		if out.level != "" {
+			// already validated
+			enabler = outputEnabler{levels: levels, min: getZapLevel(out.level)}
+		}
+		if out.messages != nil {
+			cores = append(cores, out.messages.core(cfg, cfg.newEncoder(encoding), enabler))
+			continue
+		}
+		cores = append(cores, cfg.newIOCore(encoding, out.sink, enabler))
 	}
+	core := zapcore.NewTee(cores...)
 
 	// https://github.com/uber-go/zap/blob/master/FAQ.md#why-sample-application-logs
 	// https://github.com/uber-go/zap/blob/master/FAQ.md#why-are-some-of-my-logs-missing
-	sampling := &zap.SamplingConfig{
-		Initial:    100,
-		Thereafter: 100,
+	// sampling is opt-in, otherwise logs are silently dropped in bursts
+	if !cfg.Sampling {
+		return core
 	}
+	tick := cfg.SamplingTick
+	if tick <= 0 {
+		tick = time.Second
+	}
+	hook := cfg.SamplingHook
+	return zapcore.NewSamplerWithOptions(
+		core,
+		tick,
+		cfg.SamplingInitial,
+		cfg.SamplingThereafter,
+		zapcore.SamplerHook(func(entry zapcore.Entry, dec zapcore.SamplingDecision) {
+			if dec&zapcore.LogDropped != 0 {
+				atomic.AddUint64(&counters.sampledOut, 1)
+			}
+			if hook != nil {
+				hook(entry, dec)
+			}
+		}),
+	)
+}
 
+func (cfg *Config) newEncoder(encoding string) zapcore.Encoder {
 	encoderConfig := zap.NewProductionEncoderConfig()
 
 	// if cfg.Level == (zap.AtomicLevel{}) {
 	// panic("missing Level")
 	// }
 
-	encoderConfig.EncodeTime = ZapTimeEncoder(l.DatetimeLayout)
-	if l.Encoding == "console" {
-		if l.ColorLevel {
-			encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	encoderConfig.EncodeTime = ZapTimeEncoder(cfg.DatetimeLayout)
+	encoderConfig.EncodeLevel = LowercaseLevelEncoder
+	if encoding == EncodingConsole {
+		if cfg.ColorLevel {
+			encoderConfig.EncodeLevel = CapitalColorLevelEncoder
 		}
 	}
 
-	if l.CliLevel {
+	if cfg.CliLevel {
 		encoderConfig.EncodeLevel = CliLevelEncoder
 	}
 
 	// allow set to empty to disable default ts field
-	encoderConfig.TimeKey = l.TimeKey
+	encoderConfig.TimeKey = cfg.TimeKey
 
 	// begin build
 	// zaplgr, err := cfg.Build()
@@ -234,7 +342,7 @@ func (l *LogImpl) build() *LogImpl {
 
 	// custom build
 	var enc zapcore.Encoder
-	switch l.Encoding {
+	switch encoding {
 	case EncodingConsole:
 		enc = zapcore.NewConsoleEncoder(encoderConfig)
 	case EncodingJSON:
@@ -242,83 +350,282 @@ func (l *LogImpl) build() *LogImpl {
 	case EncodingCli:
 		enc = NewCliEncoder(encoderConfig)
 	}
+	return enc
+}
+
+// newIOCore builds the core encoding the entries to sink, the time of the entries with a zero time is left out
+func (cfg *Config) newIOCore(encoding string, sink zapcore.WriteSyncer, enabler zapcore.LevelEnabler) zapcore.Core {
+	core := zapcore.NewCore(cfg.newEncoder(encoding), sink, enabler)
+	if cfg.TimeKey == "" {
+		return core
+	}
+	noTimeCfg := *cfg
+	noTimeCfg.TimeKey = ""
+	return &timeCore{
+		Core:      core,
+		newNoTime: func() zapcore.Core { return zapcore.NewCore(noTimeCfg.newEncoder(encoding), sink, enabler) },
+		noTime:    new(noTimeCore),
+	}
+}
+
+var _ zapcore.Core = (*timeCore)(nil)
+
+// timeCore writes the entries with a zero time, e.g. the slog records without time, to a core without the time key,
+// which is built on the first such entry with the fields added via With.
+type timeCore struct {
+	zapcore.Core
+	fields    []zapcore.Field
+	newNoTime func() zapcore.Core
+	noTime    *noTimeCore
+}
+
+type noTimeCore struct {
+	once sync.Once
+	core zapcore.Core
+}
+
+func (c *timeCore) With(fields []zapcore.Field) zapcore.Core {
+	return &timeCore{
+		Core:      c.Core.With(fields),
+		fields:    append(c.fields[:len(c.fields):len(c.fields)], fields...),
+		newNoTime: c.newNoTime,
+		noTime:    new(noTimeCore),
+	}
+}
+
+func (c *timeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *timeCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !ent.Time.IsZero() {
+		return c.Core.Write(ent, fields)
+	}
+	c.noTime.once.Do(func() {
+		c.noTime.core = c.newNoTime().With(c.fields)
+	})
+	return c.noTime.core.Write(ent, fields)
+}
 
-	var sink zapcore.WriteSyncer
+// validate reports all the invalid fields at once
+func (cfg *Config) validate() error {
+	errs := &ConfigError{}
+	if !validEncoding(cfg.Encoding) {
+		errs.add("Encoding", cfg.Encoding, "must be one of %s, %s or %s", EncodingConsole, EncodingJSON, EncodingCli)
+	}
+	cfg.validateOutputs(errs)
+	if cfg.Level != "" {
+		if _, err := ParseLevel(cfg.Level); err != nil {
+			errs.add("Level", cfg.Level, "%w", err)
+		}
+	}
+	if _, _, err := parseLevelRules(cfg.Levels); err != nil {
+		errs.add("Levels", cfg.Levels, "%w", err)
+	}
+	if err := checkKeysAndValues(cfg.InitialFields); err != nil {
+		errs.add("InitialFields", cfg.InitialFields, "%w", err)
+	}
+	if cfg.Sampling {
+		if cfg.SamplingInitial < 0 {
+			errs.add("SamplingInitial", cfg.SamplingInitial, "must not be negative")
+		}
+		if cfg.SamplingThereafter < 0 {
+			errs.add("SamplingThereafter", cfg.SamplingThereafter, "must not be negative")
+		}
+	}
+	if cfg.Async {
+		if cfg.AsyncBufferSize <= 0 {
+			errs.add("AsyncBufferSize", cfg.AsyncBufferSize, "must be positive")
+		}
+		if cfg.AsyncFlushInterval < 0 {
+			errs.add("AsyncFlushInterval", cfg.AsyncFlushInterval, "must not be negative")
+		}
+		if cfg.AsyncOverflow < OverflowDropNewest || cfg.AsyncOverflow > OverflowBlock {
+			errs.add("AsyncOverflow", cfg.AsyncOverflow, "unknown overflow policy")
+		}
+	}
+	if cfg.BadKeyPolicy < BadKeyFold || cfg.BadKeyPolicy > BadKeyPanic {
+		errs.add("BadKeyPolicy", cfg.BadKeyPolicy, "unknown bad key policy")
+	}
+	if rc := cfg.Rotation; rc != nil && (rc.MaxSize < 0 || rc.Interval < 0 || rc.MaxBackups < 0 || rc.MaxAge < 0) {
+		errs.add("Rotation", *rc, "MaxSize, Interval, MaxBackups and MaxAge must not be negative")
+	}
+	return errs.errOrNil()
+}
+
+func (l *LogImpl) build() (*LogImpl, error) {
+	errs := &ConfigError{Errors: l.optionErrs}
+	var cfgErr *ConfigError
+	if err := l.Config.validate(); errors.As(err, &cfgErr) {
+		errs.Errors = append(errs.Errors, cfgErr.Errors...)
+	}
+	if err := errs.errOrNil(); err != nil {
+		return nil, err
+	}
+
+	levels := newLevelRegistry(zap.InfoLevel)
+	if l.Config.Level != "" {
+		levels.root.SetLevel(getZapLevel(l.Config.Level))
+	}
+	if l.Levels != "" {
+		// already validated
+		root, names, _ := parseLevelRules(l.Levels)
+		if root != nil {
+			levels.root.SetLevel(*root)
+		}
+		levels.replaceNamed(names)
+	}
+
+	counters := &counters{}
+
+	var outs []output
 	var errSink zapcore.WriteSyncer
+	cl := &closer{}
 
-	// using custom sink if specific
-	if l.CustomSink != nil {
-		sink = zapcore.AddSync(l.CustomSink)
-		errSink = zapcore.AddSync(os.Stderr)
-	} else {
-		// otherwise using the config paths
-		var err error
-		sink, errSink, err = l.Config.openSinks()
-		if err != nil {
-			panic(err)
+	opened, err := l.Config.openSinks()
+	if err != nil {
+		return nil, err
+	}
+	outs, errSink = opened.outs, opened.errOut
+	// the sinks are closed last, after the pending entries are written
+	cl.closeSinks = opened.closers
+	if len(opened.reopenFiles) > 0 {
+		l.reopenFiles = opened.reopenFiles
+		cl.stopSignal = reopenOnSignal(opened.reopenFiles, errSink)
+	}
+
+	l.levels = levels
+	l.counters = counters
+	l.closer = cl
+
+	if l.Async {
+		// each output has its own queue, so a slow output does not delay the others
+		for i := range outs {
+			if outs[i].messages != nil {
+				// a syslog or journald message is sent per write, the async writer would merge them
+				continue
+			}
+			async := newAsyncWriter(outs[i].sink, errSink, l.AsyncBufferSize, l.AsyncFlushInterval, l.AsyncOverflow, &counters.asyncDropped)
+			cl.stopAsync = append(cl.stopAsync, async.stop)
+			outs[i].sink = async
 		}
 	}
 
 	// build the zap logger
-	zaplgr := zap.New(
-		zapcore.NewCore(enc, sink, level),
-		l.Config.buildOptions(errSink, sampling)...,
-	)
+	l.cores = newCoreHolder(l.Config, l.Config.newCore(outs, levels, counters), outs, errSink)
+	opts := l.Config.buildOptions(errSink)
+	// apply per logger name levels after sampling, so filtered entries are not counted by the sampler
+	opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newLevelCore(core, levels)
+	}))
+	zaplgr := zap.New(&swapCore{holder: l.cores}, opts...)
 	// skip ourself from caller stack
 	zaplgr = zaplgr.WithOptions(zap.AddCallerSkip(1))
 
 	if l.Name != "" {
 		zaplgr = zaplgr.Named(l.Name)
 	}
+	l.name = l.Name
 
-	if len(l.InitialFields)%2 == 0 && len(l.InitialFields) > 0 {
-		fields := make([]zap.Field, 0, len(l.InitialFields)/2)
-		for i := 0; i < len(l.InitialFields); i += 2 {
-			key, val := l.InitialFields[i], l.InitialFields[i+1]
-			fields = append(fields, zap.String(key, val))
-		}
-		zaplgr = zaplgr.With(fields...)
+	if len(l.InitialFields) > 0 {
+		// already validated, zap.Any keeps the type of the values
+		zaplgr = zaplgr.With(sweetenFields(l.InitialFields)...)
 	}
 
 	// we use the convenient sugared logger
 	zapsugar := zaplgr.Sugar()
 	l.s = zapsugar
-	return l
+	l.z = zaplgr
+	return l, nil
+}
+
+// Trace logs a message at TraceLevel, which is below DebugLevel
+func (l *LogImpl) Trace(msg string, keysAndValues ...interface{}) {
+	if ce := l.z.Check(TraceLevel, msg); ce != nil {
+		ce.Write(sweetenFields(l.normalizePairs(keysAndValues))...)
+	}
 }
 
 func (l *LogImpl) Debug(msg string, keysAndValues ...interface{}) {
-	l.s.Debugw(msg, keysAndValues...)
+	l.s.Debugw(msg, l.normalizePairs(keysAndValues)...)
 }
 
 func (l *LogImpl) Info(msg string, keysAndValues ...interface{}) {
-	l.s.Infow(msg, keysAndValues...)
+	l.s.Infow(msg, l.normalizePairs(keysAndValues)...)
 }
 
 func (l *LogImpl) Warn(msg string, keysAndValues ...interface{}) {
-	l.s.Warnw(msg, keysAndValues...)
+	l.s.Warnw(msg, l.normalizePairs(keysAndValues)...)
 }
 
 func (l *LogImpl) Error(msg string, keysAndValues ...interface{}) {
-	l.s.Errorw(msg, keysAndValues...)
+	l.s.Errorw(msg, l.normalizePairs(keysAndValues)...)
+}
+
+// DPanic logs a message at DPanicLevel, then panics if the logger is in development mode,
+// so invariant violations crash in development but are only logged in production
+func (l *LogImpl) DPanic(msg string, keysAndValues ...interface{}) {
+	l.s.DPanicw(msg, l.normalizePairs(keysAndValues)...)
+}
+
+// Panic logs a message at PanicLevel, then panics
+func (l *LogImpl) Panic(msg string, keysAndValues ...interface{}) {
+	l.s.Panicw(msg, l.normalizePairs(keysAndValues)...)
 }
 
 func (l *LogImpl) Fatal(msg string, keysAndValues ...interface{}) {
-	l.s.Fatalw(msg, keysAndValues...)
+	l.s.Fatalw(msg, l.normalizePairs(keysAndValues)...)
 }
 
 func (l *LogImpl) Sync() error {
 	return l.s.Sync()
 }
 
+// SetLevel changes the minimum enabled level at runtime, an unknown level name leaves the level unchanged,
+// use SetLevelE to get the error.
+// the level is shared with every logger derived via Named or With,
+// so the change applies to the whole logger tree.
+func (l *LogImpl) SetLevel(level string) {
+	_ = l.SetLevelE(level)
+}
+
+// SetLevelE is like SetLevel, but it reports an unknown level name, see ParseLevel
+func (l *LogImpl) SetLevelE(level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.levels.root.SetLevel(lvl)
+	return nil
+}
+
+// Level returns the current minimum enabled level, e.g. "info"
+func (l *LogImpl) Level() string {
+	return levelName(l.levels.root.Level())
+}
+
 func (l *LogImpl) Named(name string) *LogImpl {
 	newLgr := l.clone()
 	newLgr.s = l.s.Named(name)
+	newLgr.z = l.z.Named(name)
+	// keep in sync with zap.Logger.Named
+	if name != "" {
+		if l.name == "" {
+			newLgr.name = name
+		} else {
+			newLgr.name = l.name + "." + name
+		}
+	}
 	return newLgr
 }
 
 // With return a new LogImpl with context fields
 func (l *LogImpl) With(keysAndValues ...interface{}) *LogImpl {
 	newLgr := l.clone()
-	newLgr.s = l.s.With(keysAndValues...)
+	newLgr.s = l.s.With(l.normalizePairs(keysAndValues)...)
+	newLgr.z = newLgr.s.Desugar()
 	return newLgr
 }
@@ -98,7 +98,7 @@ func TestNewInvalidKeyValPairsMustPanic(t *testing.T) {
 func TestNewInvalidKeyValPairsSugarLoggerWillNotPanic(t *testing.T) {
 	t.Logf("-----------------------------------------------------------------")
 	log := NewLogger(WithName("log001"), WithEncoding("console"), WithInitialFields("version", "1.0.0"))
-	// will add an extra error log message: Ignored key without a value
+	// the key without value is logged as !BADKEY=only_key
 	log.With("only_key").Info("hello world")
 }
 
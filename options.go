@@ -2,6 +2,9 @@ package lgr
 
 import (
 	"io"
+	"time"
+
+	"go.uber.org/zap/zapcore"
 )
 
 type Option func(l *LogImpl)
@@ -11,7 +14,15 @@ func WithEncoding(encoding string) Option {
 }
 
 func WithLevel(level string) Option {
-	return func(l *LogImpl) { l.Level = level }
+	return func(l *LogImpl) { l.Config.Level = level }
+}
+
+// WithLevels sets per logger name level rules, e.g. "db=debug,http.client=warn,*=info".
+// names are the dotted names produced by WithName and Named, a rule also applies to
+// the descendants of the named logger, the most specific rule wins.
+// the wildcard "*" overrides the level set by WithLevel.
+func WithLevels(spec string) Option {
+	return func(l *LogImpl) { l.Levels = spec }
 }
 
 func WithColorLevel(enable bool) Option {
@@ -38,14 +49,19 @@ func WithDisableCaller(disableCaller bool) Option {
 	return func(l *LogImpl) { l.DisableCaller = disableCaller }
 }
 
+// WithDevelopment puts the logger in development mode, which makes DPanic panic
+// and adds stacktraces from warn level instead of error level
+func WithDevelopment(enable bool) Option {
+	return func(l *LogImpl) { l.Development = enable }
+}
+
 func WithName(loggerName string) Option {
 	return func(l *LogImpl) { l.Name = loggerName }
 }
 
-func WithInitialFields(kv ...string) Option {
-	if len(kv)%2 != 0 {
-		panic("ContextFields must in key, value pairs")
-	}
+// WithInitialFields adds key, value pairs or zap fields to the root logger,
+// a non-string key or a key without value is reported by NewLoggerE
+func WithInitialFields(kv ...interface{}) Option {
 	return func(l *LogImpl) {
 		l.InitialFields = kv
 	}
@@ -57,12 +73,105 @@ func WithOutputPaths(outputPaths ...string) Option {
 	}
 }
 
+// WithOutputs sets outputs with their own encoding and level, in addition to the output paths,
+// e.g. cli encoding at info level on stderr and json encoding at debug level in a file
+func WithOutputs(outputs ...OutputConfig) Option {
+	return func(l *LogImpl) {
+		l.Outputs = outputs
+	}
+}
+
 func WithErrorOutputPaths(errOutputPaths ...string) Option {
 	return func(l *LogImpl) {
 		l.ErrorOutputPaths = errOutputPaths
 	}
 }
 
-func WithCustomSink(writer io.Writer) Option {
-	return func(l *LogImpl) { l.CustomSink = writer }
+// WithRotation rotates the file output paths by size or interval and cleans up the rotated files by count and age,
+// a single output path can also be rotated with a rotate:///var/log/app.log?max_size=100 url, see RotateConfig.URL
+func WithRotation(rc RotateConfig) Option {
+	return func(l *LogImpl) { l.Rotation = &rc }
+}
+
+// WithReopenOnSignal makes the file output paths reopen when the process receives SIGHUP or LogImpl.Reopen is called,
+// for files managed by an external logrotate with the create mode
+func WithReopenOnSignal(enable bool) Option {
+	return func(l *LogImpl) { l.ReopenOnSignal = enable }
+}
+
+// WithAsync writes the entries in a background goroutine, so a slow sink does not block the caller.
+// at most bufferSize entries are queued, what happens when the queue is full is decided by overflowPolicy,
+// the dropped entries are reported by LogImpl.Stats.
+// queued entries are written every flushInterval, or at once if flushInterval is zero, Sync writes all queued entries.
+func WithAsync(bufferSize int, flushInterval time.Duration, overflowPolicy OverflowPolicy) Option {
+	return func(l *LogImpl) {
+		l.Async = true
+		l.AsyncBufferSize = bufferSize
+		l.AsyncFlushInterval = flushInterval
+		l.AsyncOverflow = overflowPolicy
+	}
+}
+
+// WithCustomSink adds writers to write the entries to, in addition to the output paths,
+// a zapcore.WriteSyncer is synced by Sync. the writers are not closed by Close.
+func WithCustomSink(writers ...io.Writer) Option {
+	return func(l *LogImpl) { l.CustomSinks = append(l.CustomSinks, writers...) }
+}
+
+// WithCustomErrorSink sets the writer for the logger's own errors, it is used instead of the error output paths
+func WithCustomErrorSink(writer io.Writer) Option {
+	return func(l *LogImpl) { l.CustomErrorSink = writer }
+}
+
+// WithSampling enables sampling: in each tick, the first initial entries with the same
+// level and message are logged, then only every thereafter-th entry is logged, the rest are dropped.
+// sampling is disabled by default, the number of dropped entries is reported by LogImpl.Stats.
+func WithSampling(tick time.Duration, initial, thereafter int) Option {
+	return func(l *LogImpl) {
+		l.Sampling = true
+		l.SamplingTick = tick
+		l.SamplingInitial = initial
+		l.SamplingThereafter = thereafter
+	}
+}
+
+// WithSamplingHook registers a function which is called on each sampling decision,
+// use dec&zapcore.LogDropped to count the dropped entries
+func WithSamplingHook(hook func(entry zapcore.Entry, dec zapcore.SamplingDecision)) Option {
+	return func(l *LogImpl) { l.SamplingHook = hook }
+}
+
+// WithDisableSampling disables sampling, e.g. for audit loggers which must not drop any entry,
+// false leaves the sampling setting as is, sampling is only enabled by WithSampling
+func WithDisableSampling(disableSampling bool) Option {
+	return func(l *LogImpl) {
+		if disableSampling {
+			l.Sampling = false
+		}
+	}
+}
+
+// WithContextExtractors registers functions to extract fields from the context passed to
+// DebugCtx, InfoCtx, WarnCtx and ErrorCtx, e.g. the request ID, tenant or user
+func WithContextExtractors(extractors ...ContextExtractor) Option {
+	return func(l *LogImpl) {
+		l.ContextExtractors = append(l.ContextExtractors, extractors...)
+	}
+}
+
+// WithTraceKeys names the trace correlation fields added by the *Ctx methods when the context carries
+// an OpenTelemetry span, see OTelTraceKeys and ECSTraceKeys, use TraceKeys{} to disable them.
+func WithTraceKeys(keys TraceKeys) Option {
+	return func(l *LogImpl) { l.TraceKeys = keys }
+}
+
+// WithSpanEvents mirrors the entries logged by ErrorCtx as events of the recording span carried by the context
+func WithSpanEvents(enable bool) Option {
+	return func(l *LogImpl) { l.SpanEvents = enable }
+}
+
+// WithBadKeyPolicy sets what to do with a non-string key or a key without value passed to the logging methods,
+// the default BadKeyFold logs the malformed values with the !BADKEY key
+func WithBadKeyPolicy(policy BadKeyPolicy) Option {
+	return func(l *LogImpl) { l.BadKeyPolicy = policy }
 }
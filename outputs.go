@@ -0,0 +1,255 @@
+package lgr
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// OutputConfig declares an output with its own encoding and level, see Config.Outputs
+type OutputConfig struct {
+	Paths    []string `json:"paths" yaml:"paths"`       // like Config.OutputPaths
+	Encoding string   `json:"encoding" yaml:"encoding"` // default to Config.Encoding
+	// Level is the minimum level written to the output, the entries are filtered by the logger levels first.
+	// default to no extra filtering.
+	Level string `json:"level" yaml:"level"`
+}
+
+// output is an opened output
+type output struct {
+	sink     zapcore.WriteSyncer
+	encoding string        // empty for Config.Encoding
+	level    string        // empty for no extra filtering
+	messages messageWriter // set for a syslog or journald url, which frames each entry itself
+}
+
+// messageWriter sends one message per entry, the message is framed by the core of the writer,
+// e.g. with the syslog PRI of the entry level
+type messageWriter interface {
+	zapcore.WriteSyncer
+	Close() error
+	core(cfg *Config, enc zapcore.Encoder, enabler zapcore.LevelEnabler) zapcore.Core
+}
+
+// the messages are dropped while the daemon of a message writer is unreachable,
+// the redial interval doubles after each failed dial, up to the max
+var (
+	minRedialInterval = 100 * time.Millisecond
+	maxRedialInterval = 30 * time.Second
+)
+
+// redialer delays the redials of a message writer whose daemon is unreachable, so a write does not dial each time.
+// the messages dropped meanwhile are counted and reported once, so the error output is not flooded.
+type redialer struct {
+	sink     string // e.g. syslog, for the error message
+	at       time.Time
+	interval time.Duration
+	dropped  int
+}
+
+// wait reports whether the redial is delayed, the message is counted as dropped then
+func (r *redialer) wait() bool {
+	if time.Now().Before(r.at) {
+		r.dropped++
+		return true
+	}
+	return false
+}
+
+// failed delays the next redial
+func (r *redialer) failed() {
+	if r.interval == 0 {
+		r.interval = minRedialInterval
+	} else if r.interval *= 2; r.interval > maxRedialInterval {
+		r.interval = maxRedialInterval
+	}
+	r.at = time.Now().Add(r.interval)
+}
+
+func (r *redialer) connected() {
+	r.interval = 0
+}
+
+// droppedErr reports the messages dropped since the last report, nil if there are none
+func (r *redialer) droppedErr() error {
+	if r.dropped == 0 {
+		return nil
+	}
+	err := fmt.Errorf("%s sink dropped %d messages while disconnected", r.sink, r.dropped)
+	r.dropped = 0
+	return err
+}
+
+// outputEnabler filters the entries enabled by the logger levels by the level of an output
+type outputEnabler struct {
+	levels *levelRegistry
+	min    zapcore.Level
+}
+
+func (e outputEnabler) Enabled(lvl zapcore.Level) bool {
+	return lvl >= e.min && e.levels.Enabled(lvl)
+}
+
+func validEncoding(encoding string) bool {
+	return encoding == EncodingConsole || encoding == EncodingJSON || encoding == EncodingCli
+}
+
+// validateOutputs reports the invalid entries of Outputs
+func (cfg *Config) validateOutputs(errs *ConfigError) {
+	for i, out := range cfg.Outputs {
+		if len(out.Paths) == 0 {
+			errs.add("Outputs", out, "output %d has no paths", i)
+		}
+		if out.Encoding != "" && !validEncoding(out.Encoding) {
+			errs.add("Outputs", out, "output %d encoding must be one of %s, %s or %s", i, EncodingConsole, EncodingJSON, EncodingCli)
+		}
+		if out.Level != "" {
+			if _, err := ParseLevel(out.Level); err != nil {
+				errs.add("Outputs", out, "output %d: %w", i, err)
+			}
+		}
+	}
+}
+
+// rotatePaths returns the paths, with file paths turned into rotate urls if rotation is enabled
+func (cfg *Config) rotatePaths(paths []string) []string {
+	if cfg.Rotation == nil {
+		return paths
+	}
+	rotated := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if isFilePath(path) {
+			path = cfg.Rotation.URL(strings.TrimPrefix(path, "file://"))
+		}
+		rotated = append(rotated, path)
+	}
+	return rotated
+}
+
+// sinks holds the opened outputs and error output sink
+type sinks struct {
+	outs        []output
+	errOut      zapcore.WriteSyncer
+	reopenFiles []*reopenFile
+	closers     []func()
+}
+
+func (s *sinks) close() {
+	for _, closeSink := range s.closers {
+		closeSink()
+	}
+}
+
+// open opens the paths as outputs like out, each syslog or journald url is an output of its own
+func (s *sinks) open(cfg *Config, paths []string, out output) error {
+	others, writers, err := openMessagePaths(paths)
+	if err != nil {
+		return err
+	}
+	if len(others) > 0 {
+		sink, closeOut, files, err := openPaths(cfg.rotatePaths(others), cfg.ReopenOnSignal)
+		if err != nil {
+			for _, w := range writers {
+				w.Close()
+			}
+			return err
+		}
+		out.sink = sink
+		s.outs = append(s.outs, out)
+		s.reopenFiles = append(s.reopenFiles, files...)
+		s.closers = append(s.closers, closeOut)
+	}
+	for _, w := range writers {
+		w := w
+		out.sink, out.messages = w, w
+		s.outs = append(s.outs, out)
+		s.closers = append(s.closers, func() { w.Close() })
+	}
+	return nil
+}
+
+// openMessagePaths opens the syslog and journald urls of paths, the other paths are returned as is
+func openMessagePaths(paths []string) (others []string, writers []messageWriter, err error) {
+	for _, path := range paths {
+		var w messageWriter
+		switch {
+		case isSyslogPath(path):
+			w, err = newSyslogWriter(path)
+			if err != nil {
+				err = fmt.Errorf("open syslog %q: %w", path, err)
+			}
+		case isJournaldPath(path):
+			w, err = newJournaldWriter(path)
+			if err != nil {
+				err = fmt.Errorf("open journald %q: %w", path, err)
+			}
+		default:
+			others = append(others, path)
+			continue
+		}
+		if err != nil {
+			for _, opened := range writers {
+				opened.Close()
+			}
+			return nil, nil, err
+		}
+		writers = append(writers, w)
+	}
+	return others, writers, nil
+}
+
+// openSinks opens the OutputPaths, the Outputs and the ErrorOutputPaths,
+// the CustomSinks, the deprecated CustomSink and CustomErrorSink are used as is.
+func (cfg *Config) openSinks() (_ *sinks, err error) {
+	opened := &sinks{}
+	defer func() {
+		// close the sinks opened before the error, whichever open failed
+		if err != nil {
+			opened.close()
+		}
+	}()
+	customSinks := cfg.CustomSinks
+	if cfg.CustomSink != nil {
+		customSinks = append([]io.Writer{cfg.CustomSink}, customSinks...)
+	}
+	paths := cfg.OutputPaths
+	if len(paths) == 0 && len(cfg.Outputs) == 0 && len(customSinks) == 0 {
+		paths = []string{"stderr"}
+	}
+	if len(paths) > 0 {
+		if err := opened.open(cfg, paths, output{}); err != nil {
+			errs := &ConfigError{}
+			errs.add("OutputPaths", cfg.OutputPaths, "%w", err)
+			return nil, errs
+		}
+	}
+	for i, out := range cfg.Outputs {
+		if err := opened.open(cfg, out.Paths, output{encoding: out.Encoding, level: out.Level}); err != nil {
+			errs := &ConfigError{}
+			errs.add("Outputs", out, "output %d: %w", i, err)
+			return nil, errs
+		}
+	}
+
+	for _, w := range customSinks {
+		opened.outs = append(opened.outs, output{sink: zapcore.AddSync(w)})
+	}
+
+	if cfg.CustomErrorSink != nil {
+		opened.errOut = zapcore.AddSync(cfg.CustomErrorSink)
+		return opened, nil
+	}
+	errSink, closeErrOut, errFiles, err := openPaths(cfg.ErrorOutputPaths, cfg.ReopenOnSignal)
+	if err != nil {
+		errs := &ConfigError{}
+		errs.add("ErrorOutputPaths", cfg.ErrorOutputPaths, "%w", err)
+		return nil, errs
+	}
+	opened.errOut = errSink
+	opened.reopenFiles = append(opened.reopenFiles, errFiles...)
+	opened.closers = append(opened.closers, closeErrOut)
+	return opened, nil
+}
@@ -0,0 +1,86 @@
+package lgr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutputs(t *testing.T) {
+	dir := t.TempDir()
+	console, all, errs := filepath.Join(dir, "console.log"), filepath.Join(dir, "all.log"), filepath.Join(dir, "error.log")
+	log := NewLogger(WithLevel("debug"), WithTimeKey(""), WithDisableCaller(true), WithColorLevel(false),
+		WithOutputs(
+			OutputConfig{Paths: []string{console}, Encoding: EncodingConsole, Level: "info"},
+			OutputConfig{Paths: []string{all}},
+			OutputConfig{Paths: []string{errs}, Level: "error"},
+		))
+
+	log.Debug("debug message", "uid", 7)
+	log.Info("info message")
+	log.Error("error message")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, expect := range map[string]string{
+		console: "info\tinfo message\nerror\terror message\n",
+		all: `{"level":"debug","msg":"debug message","uid":7}
+{"level":"info","msg":"info message"}
+{"level":"error","msg":"error message"}
+`,
+		errs: `{"level":"error","msg":"error message"}
+`,
+	} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expect {
+			t.Errorf("%s: expect %q, got %q", filepath.Base(path), expect, data)
+		}
+	}
+}
+
+func TestOutputsAsync(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log")
+	log := NewLogger(WithTimeKey(""), WithDisableCaller(true), WithAsync(16, 0, OverflowBlock),
+		WithOutputs(OutputConfig{Paths: []string{first}}, OutputConfig{Paths: []string{second}, Level: "warn"}))
+
+	log.Info("info message")
+	log.Warn("warn message")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, lines := range map[string]int{first: 2, second: 1} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := bytes.Count(data, []byte("\n")); n != lines {
+			t.Errorf("%s: expect %d lines, got %q", filepath.Base(path), lines, data)
+		}
+	}
+}
+
+func TestInvalidOutputs(t *testing.T) {
+	_, err := NewLoggerE(WithOutputs(
+		OutputConfig{},
+		OutputConfig{Paths: []string{"stderr"}, Encoding: "yaml", Level: "verbose"},
+	))
+	var cfgErr *ConfigError
+	if !errors.As(err, &cfgErr) || len(cfgErr.Errors) != 3 {
+		t.Fatalf("expect 3 invalid outputs, got %v", err)
+	}
+
+	_, err = NewLoggerE(WithOutputs(OutputConfig{Paths: []string{filepath.Join(t.TempDir(), "not-exist", "app.log")}}))
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Field != "Outputs" {
+		t.Fatalf("expect Outputs field error, got %v", err)
+	}
+}
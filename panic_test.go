@@ -0,0 +1,54 @@
+package lgr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func recovered(f func()) (r interface{}) {
+	defer func() { r = recover() }()
+	f()
+	return nil
+}
+
+func TestPanic(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithTimeKey(""), WithDisableCaller(true))
+
+	if r := recovered(func() { log.Panic("invariant violated", "uid", 7) }); r != "invariant violated" {
+		t.Errorf("expect Panic to panic with the message, got %v", r)
+	}
+	if got := strings.TrimSpace(buf.String()); got != `{"level":"panic","msg":"invariant violated","uid":7}` {
+		t.Errorf("unexpected output %s", got)
+	}
+}
+
+func TestDPanic(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	prod := NewLogger(WithCustomSink(buf), WithTimeKey(""), WithDisableCaller(true))
+	if r := recovered(func() { prod.DPanic("invariant violated") }); r != nil {
+		t.Errorf("expect DPanic not to panic in production, got %v", r)
+	}
+	if got := strings.TrimSpace(buf.String()); got != `{"level":"dpanic","msg":"invariant violated"}` {
+		t.Errorf("unexpected output %s", got)
+	}
+
+	dev := NewLogger(WithCustomSink(buf), WithDevelopment(true))
+	if r := recovered(func() { dev.DPanic("invariant violated") }); r != "invariant violated" {
+		t.Errorf("expect DPanic to panic in development, got %v", r)
+	}
+}
+
+func TestPanicLevels(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithLevel("dpanic"))
+	log.Error("invisible")
+	if buf.Len() != 0 || log.Level() != "dpanic" {
+		t.Errorf("expect level dpanic, got %s", log.Level())
+	}
+	log.SetLevel("panic")
+	if log.Level() != "panic" {
+		t.Errorf("expect level panic, got %s", log.Level())
+	}
+}
@@ -0,0 +1,60 @@
+package lgr
+
+import "fmt"
+
+// Debugf uses fmt.Sprintf to log a templated message
+func (l *LogImpl) Debugf(template string, args ...interface{}) {
+	l.s.Debugf(template, args...)
+}
+
+// Infof uses fmt.Sprintf to log a templated message
+func (l *LogImpl) Infof(template string, args ...interface{}) {
+	l.s.Infof(template, args...)
+}
+
+// Warnf uses fmt.Sprintf to log a templated message
+func (l *LogImpl) Warnf(template string, args ...interface{}) {
+	l.s.Warnf(template, args...)
+}
+
+// Errorf uses fmt.Sprintf to log a templated message
+func (l *LogImpl) Errorf(template string, args ...interface{}) {
+	l.s.Errorf(template, args...)
+}
+
+// Fatalf uses fmt.Sprintf to log a templated message, then calls os.Exit
+func (l *LogImpl) Fatalf(template string, args ...interface{}) {
+	l.s.Fatalf(template, args...)
+}
+
+// Debugln uses fmt.Sprintln to construct and log a message
+func (l *LogImpl) Debugln(args ...interface{}) {
+	l.s.Debug(sprintln(args))
+}
+
+// Infoln uses fmt.Sprintln to construct and log a message
+func (l *LogImpl) Infoln(args ...interface{}) {
+	l.s.Info(sprintln(args))
+}
+
+// Warnln uses fmt.Sprintln to construct and log a message
+func (l *LogImpl) Warnln(args ...interface{}) {
+	l.s.Warn(sprintln(args))
+}
+
+// Errorln uses fmt.Sprintln to construct and log a message
+func (l *LogImpl) Errorln(args ...interface{}) {
+	l.s.Error(sprintln(args))
+}
+
+// Fatalln uses fmt.Sprintln to construct and log a message, then calls os.Exit
+func (l *LogImpl) Fatalln(args ...interface{}) {
+	l.s.Fatal(sprintln(args))
+}
+
+// sprintln is fmt.Sprintln without the trailing newline,
+// spaces are always added between operands, unlike fmt.Sprint
+func sprintln(args []interface{}) string {
+	msg := fmt.Sprintln(args...)
+	return msg[:len(msg)-1]
+}
@@ -0,0 +1,33 @@
+package lgr
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintfAndPrintlnMethods(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithName("legacy"), WithLevel("debug"), WithEncoding("console"), WithCustomSink(buf), WithTimeKey(""), WithColorLevel(false))
+
+	log.Debugf("user %s logged in from %s", "user001", "127.0.0.1")
+	log.Infof("%d users online", 7)
+	log.Warnf("disk usage %.1f%%", 91.5)
+	log.Errorf("request failed: %v", "timeout")
+	log.Debugln("a", 1, "b", 2)
+	log.Infoln("uid", 7)
+	log.Warnln("retry", 3)
+	log.Errorln("failed", false)
+
+	expect := `debug	legacy	lgr/printf_test.go:12	user user001 logged in from 127.0.0.1
+info	legacy	lgr/printf_test.go:13	7 users online
+warn	legacy	lgr/printf_test.go:14	disk usage 91.5%
+error	legacy	lgr/printf_test.go:15	request failed: timeout
+debug	legacy	lgr/printf_test.go:16	a 1 b 2
+info	legacy	lgr/printf_test.go:17	uid 7
+warn	legacy	lgr/printf_test.go:18	retry 3
+error	legacy	lgr/printf_test.go:19	failed false
+`
+	if buf.String() != expect {
+		t.Errorf("unexpected output:\n%s\nexpect:\n%s", buf.String(), expect)
+	}
+}
@@ -0,0 +1,214 @@
+package lgr
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// watchInterval is the polling interval of WatchConfig
+var watchInterval = time.Second
+
+// generation is a core built from one version of the config
+type generation struct {
+	seq  uint64
+	core zapcore.Core
+}
+
+// coreHolder holds the current core, which is swapped on config reload.
+// it is shared by all loggers derived from the same root via Named and With.
+type coreHolder struct {
+	outs    []output
+	errSink zapcore.WriteSyncer
+
+	mu      sync.Mutex   // serializes the reloads
+	cfg     Config       // the config of the current core, guarded by mu
+	current atomic.Value // *generation
+}
+
+func newCoreHolder(cfg Config, core zapcore.Core, outs []output, errSink zapcore.WriteSyncer) *coreHolder {
+	h := &coreHolder{outs: outs, errSink: errSink, cfg: cfg}
+	h.current.Store(&generation{core: core})
+	return h
+}
+
+// swap must be called with mu held
+func (h *coreHolder) swap(cfg Config, core zapcore.Core) {
+	old := h.current.Load().(*generation)
+	h.cfg = cfg
+	h.current.Store(&generation{seq: old.seq + 1, core: core})
+}
+
+var _ zapcore.Core = (*swapCore)(nil)
+
+// swapCore delegates to the current core of the holder, with the fields added via With.
+// the core with the fields is built once per generation, so the fields survive a reload.
+type swapCore struct {
+	holder *coreHolder
+	fields []zapcore.Field
+
+	derived atomic.Value // *generation
+}
+
+func (c *swapCore) core() zapcore.Core {
+	cur := c.holder.current.Load().(*generation)
+	if len(c.fields) == 0 {
+		return cur.core
+	}
+	if d, ok := c.derived.Load().(*generation); ok && d.seq == cur.seq {
+		return d.core
+	}
+	d := &generation{seq: cur.seq, core: cur.core.With(c.fields)}
+	c.derived.Store(d)
+	return d.core
+}
+
+func (c *swapCore) Enabled(lvl zapcore.Level) bool {
+	return c.core().Enabled(lvl)
+}
+
+func (c *swapCore) With(fields []zapcore.Field) zapcore.Core {
+	if len(fields) == 0 {
+		return c
+	}
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	all = append(all, c.fields...)
+	all = append(all, fields...)
+	return &swapCore{holder: c.holder, fields: all}
+}
+
+func (c *swapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return c.core().Check(ent, ce)
+}
+
+func (c *swapCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.core().Write(ent, fields)
+}
+
+func (c *swapCore) Sync() error {
+	return c.core().Sync()
+}
+
+// reloadableFields are the Config fields applied by reload, besides Level and Levels
+var reloadableFields = []string{
+	"Encoding", "TimeKey", "DatetimeLayout", "ColorLevel", "CliLevel",
+	"Sampling", "SamplingTick", "SamplingInitial", "SamplingThereafter",
+}
+
+// reload applies the level, per name levels, sampling and encoder settings of cfg to the logger tree,
+// only for the config file keys present in keys, the other settings keep their current value.
+// the other settings like the output paths only take effect on a new logger.
+// nothing is changed if cfg is invalid.
+func (l *LogImpl) reload(cfg Config, keys map[string]int) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	has := func(field string) bool {
+		_, ok := keys[configKeys[field]]
+		return ok
+	}
+
+	l.cores.mu.Lock()
+	defer l.cores.mu.Unlock()
+
+	// keep the settings which are not reloadable, e.g. the sampling hook set by an option
+	reloaded := l.cores.cfg
+	src, dst := reflect.ValueOf(cfg), reflect.ValueOf(&reloaded).Elem()
+	changed := false
+	for _, field := range reloadableFields {
+		if has(field) {
+			dst.FieldByName(field).Set(src.FieldByName(field))
+			changed = true
+		}
+	}
+	if changed {
+		l.cores.swap(reloaded, reloaded.newCore(l.cores.outs, l.levels, l.counters))
+	}
+
+	if has("Level") {
+		root := zap.InfoLevel
+		if cfg.Level != "" {
+			root = getZapLevel(cfg.Level)
+		}
+		l.levels.root.SetLevel(root)
+	}
+	if has("Levels") {
+		// already validated
+		rootRule, names, _ := parseLevelRules(cfg.Levels)
+		if rootRule != nil {
+			l.levels.root.SetLevel(*rootRule)
+		}
+		l.levels.replaceNamed(names)
+	}
+	return nil
+}
+
+// WatchConfig applies the config file at path to the logger tree of l, then polls the file for changes.
+// the level, per name levels, sampling and encoder settings are reloaded,
+// the other settings like the output paths only take effect on a new logger, see NewFromConfig.
+// only the keys present in the file are applied, the others keep their current value,
+// e.g. a file with only "levels: db=warn" leaves the encoding and the root level as is.
+// an invalid edit is reported to the error output and the running logger is left untouched.
+// the returned function stops watching.
+func WatchConfig(path string, l *LogImpl) (stop func(), err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := parseConfig(path, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.reload(cfg, keyLines(data)); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			current, err := os.ReadFile(path)
+			if err != nil || bytes.Equal(current, data) {
+				// e.g. the file is being replaced, retry on the next tick
+				continue
+			}
+			data = current
+			if err := l.reloadConfig(path, data); err != nil {
+				fmt.Fprintf(l.cores.errSink, "%v failed to reload log config: %v\n", time.Now(), err)
+				_ = l.cores.errSink.Sync()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}, nil
+}
+
+func (l *LogImpl) reloadConfig(path string, data []byte) error {
+	cfg, err := parseConfig(path, data)
+	if err != nil {
+		return err
+	}
+	return l.reload(cfg, keyLines(data))
+}
@@ -0,0 +1,146 @@
+package lgr
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchConfigAppliesFile(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithTimeKey(""), WithDisableCaller(true))
+	child := log.Named("db").With("uid", 7)
+
+	path := writeConfig(t, "log.yaml", "encoding: console\ncolor_level: false\ntime_key: \"\"\nlevel: debug\nlevels: db=warn\n")
+	stop, err := WatchConfig(path, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	log.Debug("root debug")
+	child.Info("invisible")
+	child.Warn("db warn")
+
+	expect := "debug\troot debug\nwarn\tdb\tdb warn\t{\"uid\": 7}\n"
+	if got := buf.String(); got != expect {
+		t.Errorf("expect %q, got %q", expect, got)
+	}
+	if log.Level() != "debug" || log.NamedLevel("db") != "warn" {
+		t.Errorf("unexpected levels %s %s", log.Level(), log.NamedLevel("db"))
+	}
+}
+
+func TestWatchConfigReloadsOnChange(t *testing.T) {
+	defer func(interval time.Duration) { watchInterval = interval }(watchInterval)
+	watchInterval = 10 * time.Millisecond
+
+	dir := t.TempDir()
+	out, errOut := filepath.Join(dir, "app.log"), filepath.Join(dir, "error.log")
+	log := NewLogger(WithOutputPaths(out), WithErrorOutputPaths(errOut), WithTimeKey(""), WithDisableCaller(true))
+	defer log.Close(context.Background())
+	child := log.With("uid", 7)
+
+	path := writeConfig(t, "log.yaml", "time_key: \"\"\nlevel: info\n")
+	stop, err := WatchConfig(path, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	// an invalid edit is reported and the running logger is left untouched
+	if err := os.WriteFile(path, []byte("time_key: \"\"\nlevel: verbose\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		data, _ := os.ReadFile(errOut)
+		return strings.Contains(string(data), "failed to reload log config") && strings.Contains(string(data), "verbose")
+	})
+	child.Debug("invisible")
+	child.Info("still json")
+
+	if err := os.WriteFile(path, []byte("time_key: \"\"\nlevel: debug\nsampling: true\nsampling_initial: 1\nsampling_thereafter: 0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return log.Level() == "debug" })
+	child.Debug("sampled")
+	child.Debug("sampled")
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"level":"info","msg":"still json","uid":7}
+{"level":"debug","msg":"sampled","uid":7}
+`
+	if string(data) != expect {
+		t.Errorf("expect %s, got %s", expect, data)
+	}
+	if n := log.Stats().SampledOut; n != 1 {
+		t.Errorf("expect 1 sampled out entry, got %d", n)
+	}
+}
+
+func TestWatchConfigInvalidFile(t *testing.T) {
+	log := NewLogger(WithCustomSink(bytes.NewBuffer(nil)))
+	if _, err := WatchConfig(writeConfig(t, "log.yaml", "encoding: yaml\n"), log); err == nil {
+		t.Errorf("expect error for invalid config")
+	}
+	if _, err := WatchConfig(filepath.Join(t.TempDir(), "missing.yaml"), log); err == nil {
+		t.Errorf("expect error for missing config")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for the condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWatchConfigMinimalFile(t *testing.T) {
+	defer func(interval time.Duration) { watchInterval = interval }(watchInterval)
+	watchInterval = 10 * time.Millisecond
+
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithEncoding("console"), WithColorLevel(false), WithTimeKey(""),
+		WithDisableCaller(true), WithLevel("debug"))
+
+	path := writeConfig(t, "log.yaml", "levels: db=warn\n")
+	stop, err := WatchConfig(path, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	log.Debug("root debug")
+	log.Named("db").Info("invisible")
+	if expect := "debug\troot debug\n"; buf.String() != expect {
+		t.Errorf("expect %q, got %q", expect, buf.String())
+	}
+
+	// the keys of an earlier version are kept when a later version drops them
+	buf.Reset()
+	if err := os.WriteFile(path, []byte("encoding: json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		buf.Reset()
+		log.Debug("root debug")
+		return strings.HasPrefix(buf.String(), "{")
+	})
+	if log.Level() != "debug" || log.NamedLevel("db") != "warn" {
+		t.Errorf("expect levels kept, got %s %s", log.Level(), log.NamedLevel("db"))
+	}
+	if expect := `{"level":"debug","msg":"root debug"}` + "\n"; buf.String() != expect {
+		t.Errorf("expect %q, got %q", expect, buf.String())
+	}
+}
@@ -0,0 +1,166 @@
+package lgr
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// reopenFile is a file sink which can be reopened after the file has been moved away,
+// e.g. by logrotate with the create mode
+type reopenFile struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newReopenFile(path string) (*reopenFile, error) {
+	f := &reopenFile{path: path}
+	file, err := f.open()
+	if err != nil {
+		return nil, err
+	}
+	f.file = file
+	return f, nil
+}
+
+func (f *reopenFile) open() (*os.File, error) {
+	// O_APPEND makes the writes continue at the end of a file truncated by copytruncate
+	return os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+}
+
+func (f *reopenFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Write(p)
+}
+
+func (f *reopenFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Sync()
+}
+
+func (f *reopenFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// Reopen reopens the file if it has been moved away or removed,
+// nothing is done if the path still refers to the opened file.
+func (f *reopenFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	opened, err := f.file.Stat()
+	if err != nil {
+		return err
+	}
+	if onDisk, err := os.Stat(f.path); err == nil && os.SameFile(opened, onDisk) {
+		return nil
+	}
+
+	file, err := f.open()
+	if err != nil {
+		return err
+	}
+	old := f.file
+	f.file = file
+	// the old file may have been synced and closed by the rotation tool already
+	_ = old.Sync()
+	return old.Close()
+}
+
+// openPaths opens the paths like zap.Open does,
+// but the file paths are opened as reopenable files if reopen is true.
+func openPaths(paths []string, reopen bool) (zapcore.WriteSyncer, func(), []*reopenFile, error) {
+	if !reopen {
+		sink, closeSink, err := zap.Open(paths...)
+		return sink, closeSink, nil, err
+	}
+
+	var files []*reopenFile
+	var others []string
+	for _, path := range paths {
+		if !isFilePath(path) {
+			others = append(others, path)
+			continue
+		}
+		f, err := newReopenFile(strings.TrimPrefix(path, "file://"))
+		if err != nil {
+			for _, opened := range files {
+				opened.Close()
+			}
+			return nil, nil, nil, err
+		}
+		files = append(files, f)
+	}
+
+	syncers := make([]zapcore.WriteSyncer, 0, len(files)+1)
+	for _, f := range files {
+		syncers = append(syncers, f)
+	}
+	closeOthers := func() {}
+	if len(others) > 0 {
+		sink, closeSink, err := zap.Open(others...)
+		if err != nil {
+			for _, f := range files {
+				f.Close()
+			}
+			return nil, nil, nil, err
+		}
+		syncers = append(syncers, sink)
+		closeOthers = closeSink
+	}
+
+	closeAll := func() {
+		for _, f := range files {
+			f.Close()
+		}
+		closeOthers()
+	}
+	return zap.CombineWriteSyncers(syncers...), closeAll, files, nil
+}
+
+// reopenOnSignal reopens the files on SIGHUP, errors are written to errSink.
+// the returned function stops the signal handling.
+func reopenOnSignal(files []*reopenFile, errSink zapcore.WriteSyncer) func() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	go func() {
+		for range ch {
+			if err := reopenAll(files); err != nil {
+				fmt.Fprintf(errSink, "%v failed to reopen log files: %v\n", time.Now(), err)
+				_ = errSink.Sync()
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(ch)
+	}
+}
+
+func reopenAll(files []*reopenFile) error {
+	var err error
+	for _, f := range files {
+		err = multierr.Append(err, f.Reopen())
+	}
+	return err
+}
+
+// Reopen reopens the file output paths which have been moved away or removed,
+// e.g. by logrotate, this only has effect if the logger is built with WithReopenOnSignal(true).
+func (l *LogImpl) Reopen() error {
+	return reopenAll(l.reopenFiles)
+}
@@ -0,0 +1,99 @@
+package lgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func readFile(t *testing.T, path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	log := NewLogger(WithOutputPaths(path), WithReopenOnSignal(true), WithTimeKey(""), WithDisableCaller(true))
+	defer log.Close(context.Background())
+
+	log.Info("before rotation")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	log.Info("moved away")
+
+	// the file has been moved away, so Reopen from a derived logger creates a new file at path,
+	// which is the one written by the whole logger tree
+	if err := log.Named("child").Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("expect a new file at %s: %v", path, err)
+	}
+	if opened, err := log.reopenFiles[0].file.Stat(); err != nil || !os.SameFile(info, opened) {
+		t.Errorf("expect the new file to be the one written, got %v", err)
+	}
+	log.Info("after rotation")
+
+	// the file at path is already the one written, Reopen keeps appending to it
+	if err := log.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	log.Info("after second reopen")
+
+	if got := readFile(t, path+".1"); got != "{\"level\":\"info\",\"msg\":\"before rotation\"}\n{\"level\":\"info\",\"msg\":\"moved away\"}\n" {
+		t.Errorf("unexpected rotated file content %q", got)
+	}
+	if got := readFile(t, path); got != "{\"level\":\"info\",\"msg\":\"after rotation\"}\n{\"level\":\"info\",\"msg\":\"after second reopen\"}\n" {
+		t.Errorf("unexpected log file content %q", got)
+	}
+}
+
+func TestReopenOnSIGHUP(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGHUP is not supported on windows")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	log := NewLogger(WithOutputPaths(path), WithReopenOnSignal(true), WithTimeKey(""), WithDisableCaller(true))
+	defer log.Close(context.Background())
+
+	log.Info("before rotation")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	proc, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := proc.Signal(syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("log file is not reopened after SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	log.Info("after rotation")
+	if got := readFile(t, path); !strings.Contains(got, "after rotation") {
+		t.Errorf("unexpected log file content %q", got)
+	}
+}
@@ -0,0 +1,431 @@
+package lgr
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+const (
+	// RotateScheme is the url scheme of the rotating file sink,
+	// e.g. rotate:///var/log/app.log?max_size=100&interval=24&max_backups=3&max_age=7&compress=true&localtime=true
+	RotateScheme = "rotate"
+
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+	defaultMaxSize   = 100
+	// a failed rotation is retried after the interval, the entries are appended to the current file meanwhile
+	rotateRetryInterval = time.Minute
+)
+
+// variables for testing
+var (
+	currentTime = time.Now
+	megabyte    = int64(1024 * 1024)
+	osRename    = os.Rename
+)
+
+// RotateConfig configures the rotating file sink
+type RotateConfig struct {
+	MaxSize    int  `json:"max_size" yaml:"max_size"`       // max size in megabytes of the log file before it gets rotated, default to 100
+	Interval   int  `json:"interval" yaml:"interval"`       // max number of hours since the log file was opened before it gets rotated, 0 means rotate by size only
+	MaxBackups int  `json:"max_backups" yaml:"max_backups"` // max number of rotated files to retain, 0 means retain all
+	MaxAge     int  `json:"max_age" yaml:"max_age"`         // max number of days to retain rotated files, 0 means no age limit
+	Compress   bool `json:"compress" yaml:"compress"`       // gzip the rotated files
+	LocalTime  bool `json:"local_time" yaml:"local_time"`   // use local time instead of UTC in the rotated file names
+}
+
+// URL returns the rotate:// url for the file path with this config
+func (rc RotateConfig) URL(path string) string {
+	q := url.Values{}
+	q.Set("max_size", strconv.Itoa(rc.MaxSize))
+	q.Set("interval", strconv.Itoa(rc.Interval))
+	q.Set("max_backups", strconv.Itoa(rc.MaxBackups))
+	q.Set("max_age", strconv.Itoa(rc.MaxAge))
+	q.Set("compress", strconv.FormatBool(rc.Compress))
+	q.Set("localtime", strconv.FormatBool(rc.LocalTime))
+	u := url.URL{Scheme: RotateScheme, Path: path, RawQuery: q.Encode()}
+	return u.String()
+}
+
+func parseRotateURL(u *url.URL) (RotateConfig, error) {
+	var rc RotateConfig
+	if u.Path == "" {
+		return rc, fmt.Errorf("missing file path in %s url %q", RotateScheme, u.String())
+	}
+	if u.Host != "" {
+		return rc, fmt.Errorf("%s url %q must have an absolute path, e.g. %s:///var/log/app.log", RotateScheme, u.String(), RotateScheme)
+	}
+
+	q := u.Query()
+	ints := map[string]*int{"max_size": &rc.MaxSize, "interval": &rc.Interval, "max_backups": &rc.MaxBackups, "max_age": &rc.MaxAge}
+	for key, ptr := range ints {
+		if v := q.Get(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return rc, fmt.Errorf("invalid %s %q in %s url, must be a non-negative integer", key, v, RotateScheme)
+			}
+			*ptr = n
+		}
+	}
+	bools := map[string]*bool{"compress": &rc.Compress, "localtime": &rc.LocalTime}
+	for key, ptr := range bools {
+		if v := q.Get(key); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return rc, fmt.Errorf("invalid %s %q in %s url, must be a boolean", key, v, RotateScheme)
+			}
+			*ptr = b
+		}
+	}
+	return rc, nil
+}
+
+func newRotateSink(u *url.URL) (zap.Sink, error) {
+	rc, err := parseRotateURL(u)
+	if err != nil {
+		return nil, err
+	}
+	return newRotatingFile(u.Path, rc)
+}
+
+// isFilePath reports whether the output path is a plain file, rather than stdout, stderr or an url
+func isFilePath(path string) bool {
+	if path == "stdout" || path == "stderr" {
+		return false
+	}
+	u, err := url.Parse(path)
+	if err != nil || u.Scheme == "" {
+		return true
+	}
+	// windows drive letters are parsed as scheme
+	return u.Scheme == "file" || len(u.Scheme) == 1
+}
+
+// rotatingFile is a zap.Sink which rotates the file once it reaches the max size or the interval elapses,
+// rotated files are named after the rotation time, e.g. app-2006-01-02T15-04-05.000.log
+type rotatingFile struct {
+	filename string
+	cfg      RotateConfig
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	closed   bool
+	retryAt  time.Time
+
+	millCh   chan struct{}
+	millDone chan struct{}
+}
+
+func newRotatingFile(filename string, cfg RotateConfig) (*rotatingFile, error) {
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = defaultMaxSize
+	}
+	r := &rotatingFile{
+		filename: filename,
+		cfg:      cfg,
+		millCh:   make(chan struct{}, 1),
+		millDone: make(chan struct{}),
+	}
+	if err := r.openExistingOrNew(); err != nil {
+		return nil, err
+	}
+	go r.millRun()
+	// clean up the backups left by a previous run
+	r.mill()
+	return r, nil
+}
+
+func (r *rotatingFile) maxSize() int64 {
+	return int64(r.cfg.MaxSize) * megabyte
+}
+
+// shouldRotate reports whether the file would exceed the max size with n more bytes, or the interval has elapsed
+func (r *rotatingFile) shouldRotate(n int) bool {
+	if r.size+int64(n) > r.maxSize() {
+		return true
+	}
+	return r.cfg.Interval > 0 && !currentTime().Before(r.openedAt.Add(time.Duration(r.cfg.Interval)*time.Hour))
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	var rotateErr error
+	if r.file != nil && r.size > 0 && r.shouldRotate(len(p)) && !currentTime().Before(r.retryAt) {
+		rotateErr = r.rotate()
+	}
+	if r.file == nil {
+		// the file could not be reopened by a previous rotation
+		if err := r.openExistingOrNew(); err != nil {
+			return 0, multierr.Append(rotateErr, err)
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	// the entry is written, the rotation error is still reported
+	return n, rotateErr
+}
+
+func (r *rotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || r.file == nil {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+// Close closes the file and waits for the pending compression and clean up of rotated files
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+	}
+	close(r.millCh)
+	r.mu.Unlock()
+
+	<-r.millDone
+	return err
+}
+
+func (r *rotatingFile) openExistingOrNew() error {
+	if err := os.MkdirAll(filepath.Dir(r.filename), 0o755); err != nil {
+		return fmt.Errorf("can not make directory for log file: %w", err)
+	}
+	f, err := os.OpenFile(r.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file = f
+	r.size = info.Size()
+	r.openedAt = currentTime()
+	return nil
+}
+
+// rotate must be called with the lock held.
+// the file is reopened even if the rename fails, so the entries keep being written to the current file
+// until the rotation is retried, r.file is nil if it can not be reopened.
+func (r *rotatingFile) rotate() error {
+	err := r.file.Close()
+	r.file = nil
+	if renameErr := osRename(r.filename, r.backupName(currentTime())); renameErr != nil {
+		err = multierr.Append(err, fmt.Errorf("can not rename log file: %w", renameErr))
+		r.retryAt = currentTime().Add(rotateRetryInterval)
+	} else {
+		r.mill()
+	}
+	return multierr.Append(err, r.openExistingOrNew())
+}
+
+// backupName returns an unused name for the rotated file,
+// a sequence number is appended to the time if the file was rotated in the same millisecond, e.g. app-2006-01-02T15-04-05.000-1.log
+func (r *rotatingFile) backupName(t time.Time) string {
+	dir := filepath.Dir(r.filename)
+	prefix, ext := r.prefixAndExt()
+	if !r.cfg.LocalTime {
+		t = t.UTC()
+	}
+	ts := t.Format(backupTimeFormat)
+	name := filepath.Join(dir, prefix+ts+ext)
+	for seq := 1; fileExists(name) || fileExists(name+compressSuffix); seq++ {
+		name = filepath.Join(dir, prefix+ts+"-"+strconv.Itoa(seq)+ext)
+	}
+	return name
+}
+
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+func (r *rotatingFile) prefixAndExt() (prefix, ext string) {
+	base := filepath.Base(r.filename)
+	ext = filepath.Ext(base)
+	return base[:len(base)-len(ext)] + "-", ext
+}
+
+// mill triggers the compression and clean up of rotated files in background
+func (r *rotatingFile) mill() {
+	select {
+	case r.millCh <- struct{}{}:
+	default:
+	}
+}
+
+func (r *rotatingFile) millRun() {
+	defer close(r.millDone)
+	for range r.millCh {
+		// errors are ignored, there is no way to report them without logging recursively
+		_ = r.millRunOnce()
+	}
+}
+
+type backupFile struct {
+	path string
+	t    time.Time
+	seq  int
+}
+
+func (r *rotatingFile) millRunOnce() error {
+	if r.cfg.MaxBackups == 0 && r.cfg.MaxAge == 0 && !r.cfg.Compress {
+		return nil
+	}
+
+	backups, err := r.backups()
+	if err != nil {
+		return err
+	}
+
+	var remove, compress []backupFile
+	if r.cfg.MaxBackups > 0 && len(backups) > r.cfg.MaxBackups {
+		remove = append(remove, backups[r.cfg.MaxBackups:]...)
+		backups = backups[:r.cfg.MaxBackups]
+	}
+	if r.cfg.MaxAge > 0 {
+		cutoff := currentTime().Add(-time.Duration(r.cfg.MaxAge) * 24 * time.Hour)
+		kept := backups[:0]
+		for _, b := range backups {
+			if b.t.Before(cutoff) {
+				remove = append(remove, b)
+			} else {
+				kept = append(kept, b)
+			}
+		}
+		backups = kept
+	}
+	if r.cfg.Compress {
+		for _, b := range backups {
+			if !strings.HasSuffix(b.path, compressSuffix) {
+				compress = append(compress, b)
+			}
+		}
+	}
+
+	var errs []string
+	for _, b := range remove {
+		if err := os.Remove(b.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err.Error())
+		}
+	}
+	for _, b := range compress {
+		if err := compressFile(b.path, b.path+compressSuffix); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// backups returns the rotated files, newest first
+func (r *rotatingFile) backups() ([]backupFile, error) {
+	entries, err := os.ReadDir(filepath.Dir(r.filename))
+	if err != nil {
+		return nil, err
+	}
+
+	loc := time.UTC
+	if r.cfg.LocalTime {
+		loc = time.Local
+	}
+	prefix, ext := r.prefixAndExt()
+	var backups []backupFile
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), compressSuffix)
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts, seq := name[len(prefix):len(name)-len(ext)], 0
+		if len(ts) > len(backupTimeFormat) {
+			// the sequence number of a file rotated in the same millisecond
+			n, err := strconv.Atoi(strings.TrimPrefix(ts[len(backupTimeFormat):], "-"))
+			if err != nil || n <= 0 {
+				continue
+			}
+			ts, seq = ts[:len(backupTimeFormat)], n
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, ts, loc)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(filepath.Dir(r.filename), e.Name()), t: t, seq: seq})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].t.Equal(backups[j].t) {
+			return backups[i].t.After(backups[j].t)
+		}
+		return backups[i].seq > backups[j].seq
+	})
+	return backups, nil
+}
+
+func compressFile(src, dst string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	gzf, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(gzf)
+	if _, err := io.Copy(gz, f); err != nil {
+		gzf.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		gzf.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := gzf.Close(); err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(src)
+}
@@ -0,0 +1,269 @@
+package lgr
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fakeTime(t *testing.T, start time.Time) func(d time.Duration) {
+	now := start
+	currentTime = func() time.Time { return now }
+	megabyte = 1
+	t.Cleanup(func() {
+		currentTime = time.Now
+		megabyte = 1024 * 1024
+	})
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+func listDir(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestRotatingFile(t *testing.T) {
+	advance := fakeTime(t, time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC))
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	r, err := newRotatingFile(filename, RotateConfig{MaxSize: 10, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"line-001\n", "line-002\n", "line-003\n", "line-004\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		advance(time.Second)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Join(listDir(t, dir), " ")
+	expect := "app-2022-01-02T03-04-07.000.log.gz app-2022-01-02T03-04-08.000.log.gz app.log"
+	if got != expect {
+		t.Fatalf("unexpected files: %s, expect %s", got, expect)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "line-004\n" {
+		t.Errorf("unexpected log file content %q", content)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "app-2022-01-02T03-04-08.000.log.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err = io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "line-003\n" {
+		t.Errorf("unexpected rotated file content %q", content)
+	}
+}
+
+func TestRotatingFileRenameError(t *testing.T) {
+	advance := fakeTime(t, time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC))
+	renameErr := errors.New("cross-device link")
+	osRename = func(string, string) error { return renameErr }
+	t.Cleanup(func() { osRename = os.Rename })
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	r, err := newRotatingFile(filename, RotateConfig{MaxSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Write([]byte("line-001\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Write([]byte("line-002\n")); !errors.Is(err, renameErr) || !strings.Contains(err.Error(), "can not rename log file") {
+		t.Errorf("expect rename error, got %v", err)
+	}
+	// the rotation is not retried until the retry interval elapses
+	if _, err := r.Write([]byte("line-003\n")); err != nil {
+		t.Errorf("expect no rotation before the retry, got %v", err)
+	}
+	osRename = os.Rename
+	advance(rotateRetryInterval)
+	if _, err := r.Write([]byte("line-004\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expect := range map[string]string{
+		"app.log":                         "line-004\n",
+		"app-2022-01-02T03-05-05.000.log": "line-001\nline-002\nline-003\n",
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != expect {
+			t.Errorf("%s: expect %q, got %q", name, expect, content)
+		}
+	}
+}
+
+func TestRotatingFileMaxAge(t *testing.T) {
+	advance := fakeTime(t, time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC))
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	r, err := newRotatingFile(filename, RotateConfig{MaxSize: 10, MaxAge: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = r.Write([]byte("line-001\n"))
+	_, _ = r.Write([]byte("line-002\n"))
+	advance(48 * time.Hour)
+	_, _ = r.Write([]byte("line-003\n"))
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Join(listDir(t, dir), " ")
+	expect := "app-2022-01-04T03-04-05.000.log app.log"
+	if got != expect {
+		t.Fatalf("unexpected files: %s, expect %s", got, expect)
+	}
+}
+
+func TestRotatingFileInterval(t *testing.T) {
+	advance := fakeTime(t, time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC))
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	r, err := newRotatingFile(filename, RotateConfig{MaxSize: 100, Interval: 24})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = r.Write([]byte("line-001\n"))
+	advance(23 * time.Hour)
+	_, _ = r.Write([]byte("line-002\n"))
+	advance(time.Hour)
+	_, _ = r.Write([]byte("line-003\n"))
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expect := range map[string]string{
+		"app.log":                         "line-003\n",
+		"app-2022-01-03T03-04-05.000.log": "line-001\nline-002\n",
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != expect {
+			t.Errorf("%s: expect %q, got %q", name, expect, content)
+		}
+	}
+}
+
+func TestParseRotateURL(t *testing.T) {
+	u, _ := url.Parse(RotateConfig{MaxSize: 10, Interval: 24, MaxBackups: 3, MaxAge: 7, Compress: true, LocalTime: true}.URL("/var/log/app.log"))
+	rc, err := parseRotateURL(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc != (RotateConfig{MaxSize: 10, Interval: 24, MaxBackups: 3, MaxAge: 7, Compress: true, LocalTime: true}) || u.Path != "/var/log/app.log" {
+		t.Errorf("unexpected config %+v from %s", rc, u)
+	}
+
+	for _, raw := range []string{"rotate://", "rotate://app.log", "rotate:///app.log?max_size=-1", "rotate:///app.log?compress=maybe"} {
+		u, _ := url.Parse(raw)
+		if _, err := parseRotateURL(u); err == nil {
+			t.Errorf("expect error for %s", raw)
+		}
+	}
+}
+
+func TestNewLoggerWithRotation(t *testing.T) {
+	fakeTime(t, time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC))
+	dir := t.TempDir()
+
+	log := NewLogger(WithOutputPaths(filepath.Join(dir, "app.log")), WithRotation(RotateConfig{MaxSize: 100}), WithTimeKey(""))
+	log.Info("this is a info message", "uid", 7, "name", "user001")
+	log.Info("this is a info message", "uid", 8, "name", "user002")
+
+	log2 := NewLogger(WithOutputPaths(RotateScheme+"://"+filepath.Join(dir, "other.log")+"?max_size=100"), WithTimeKey(""))
+	log2.Info("this is a info message", "uid", 7, "name", "user001")
+	log2.Info("this is a info message", "uid", 8, "name", "user002")
+
+	for _, l := range []*LogImpl{log, log2} {
+		if err := l.Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := strings.Join(listDir(t, dir), " ")
+	expect := "app-2022-01-02T03-04-05.000.log app.log other-2022-01-02T03-04-05.000.log other.log"
+	if got != expect {
+		t.Fatalf("unexpected files: %s, expect %s", got, expect)
+	}
+}
+
+func TestRotatingFileSameMillisecond(t *testing.T) {
+	fakeTime(t, time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC))
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	r, err := newRotatingFile(filename, RotateConfig{MaxSize: 1, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"line-001\n", "line-002\n", "line-003\n", "line-004\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the oldest backup is removed, the newer ones have the greater sequence numbers
+	for name, expect := range map[string]string{
+		"app.log":                           "line-004\n",
+		"app-2022-01-02T03-04-05.000-2.log": "line-003\n",
+		"app-2022-01-02T03-04-05.000-1.log": "line-002\n",
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != expect {
+			t.Errorf("%s: expect %q, got %q", name, expect, content)
+		}
+	}
+	if files := listDir(t, dir); len(files) != 3 {
+		t.Errorf("expect 2 backups and the log file, got %v", files)
+	}
+}
@@ -0,0 +1,71 @@
+package lgr
+
+import (
+	"bytes"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSamplingDisabledByDefault(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf))
+	for i := 0; i < 300; i++ {
+		log.Info("same message")
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 300 {
+		t.Errorf("expect all 300 entries logged, got %d", n)
+	}
+	if log.Stats().SampledOut != 0 {
+		t.Errorf("expect no sampled out entries, got %d", log.Stats().SampledOut)
+	}
+}
+
+func TestWithSampling(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	var hooked uint64
+	log := NewLogger(WithCustomSink(buf), WithSampling(time.Minute, 2, 3), WithSamplingHook(func(entry zapcore.Entry, dec zapcore.SamplingDecision) {
+		if dec&zapcore.LogDropped != 0 {
+			atomic.AddUint64(&hooked, 1)
+		}
+	}))
+	child := log.Named("child")
+	for i := 0; i < 10; i++ {
+		child.Info("same message")
+	}
+	// 1st, 2nd, 5th and 8th are logged
+	if n := strings.Count(buf.String(), "\n"); n != 4 {
+		t.Errorf("expect 4 entries logged, got %d", n)
+	}
+	if got := log.Stats().SampledOut; got != 6 {
+		t.Errorf("expect 6 sampled out entries, got %d", got)
+	}
+	if got := atomic.LoadUint64(&hooked); got != 6 {
+		t.Errorf("expect hook called for 6 dropped entries, got %d", got)
+	}
+}
+
+func TestWithDisableSampling(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithSampling(time.Minute, 1, 0), WithDisableSampling(true))
+	for i := 0; i < 10; i++ {
+		log.Info("audit")
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 10 {
+		t.Errorf("expect all 10 entries logged, got %d", n)
+	}
+}
+
+func TestWithDisableSamplingFalse(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithDisableSampling(false))
+	for i := 0; i < 200; i++ {
+		log.Info("not sampled")
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 200 {
+		t.Errorf("expect all 200 entries logged, got %d", n)
+	}
+}
@@ -0,0 +1,233 @@
+//go:build go1.21
+
+package lgr
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/ttys3/lgr/internal/bufferpool"
+)
+
+// slogHandler is a slog.Handler which writes the records through the zap logger of a LogImpl,
+// so the records share the level, encoder, sinks and error output of the logger.
+// the caller and stacktrace are taken from the record, as the zap ones would point into slog.
+type slogHandler struct {
+	logger    *zap.Logger
+	addCaller bool
+	addStack  zapcore.LevelEnabler
+
+	// groups are opened lazily, so a group without any attribute is omitted as slog requires
+	groups []string
+}
+
+var _ slog.Handler = (*slogHandler)(nil)
+
+// SlogHandler returns a slog.Handler backed by the logger,
+// the fields added via With and the logger name are kept.
+func (l *LogImpl) SlogHandler() slog.Handler {
+	return &slogHandler{
+		logger:    l.z.WithOptions(zap.WithCaller(false), zap.AddStacktrace(zap.LevelEnablerFunc(func(zapcore.Level) bool { return false }))),
+		addCaller: !l.DisableCaller,
+		addStack:  l.Config.stackLevel(),
+	}
+}
+
+// Slog returns a *slog.Logger backed by the logger, see SlogHandler
+func (l *LogImpl) Slog() *slog.Logger {
+	return slog.New(l.SlogHandler())
+}
+
+// slogToZapLevel maps the slog levels to the nearest lower zap level
+func slogToZapLevel(l slog.Level) zapcore.Level {
+	switch {
+	case l >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case l >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case l >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	case l >= slog.LevelDebug:
+		return zapcore.DebugLevel
+	default:
+		return TraceLevel
+	}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Core().Enabled(slogToZapLevel(level))
+}
+
+func (h *slogHandler) Handle(_ context.Context, record slog.Record) error {
+	lvl := slogToZapLevel(record.Level)
+	ce := h.logger.Check(lvl, record.Message)
+	if ce == nil {
+		return nil
+	}
+	// a zero time is left out by the encoder, see timeCore
+	ce.Time = record.Time
+
+	if h.addCaller && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		if frame.PC != 0 {
+			ce.Caller = zapcore.EntryCaller{
+				Defined:  true,
+				PC:       frame.PC,
+				File:     frame.File,
+				Line:     frame.Line,
+				Function: frame.Function,
+			}
+		}
+	}
+
+	if h.addStack != nil && h.addStack.Enabled(lvl) {
+		ce.Stack = slogStack(record.PC)
+	}
+
+	fields := make([]zapcore.Field, len(h.groups), len(h.groups)+record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendSlogAttr(fields, attr)
+		return true
+	})
+	if len(fields) == len(h.groups) {
+		// all the attrs are empty, so the groups are omitted too
+		fields = fields[:0]
+	}
+	h.fillGroups(fields)
+	ce.Write(fields...)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]zapcore.Field, len(h.groups), len(h.groups)+len(attrs))
+	for _, attr := range attrs {
+		fields = appendSlogAttr(fields, attr)
+	}
+	if len(fields) == len(h.groups) {
+		return h
+	}
+	h.fillGroups(fields)
+
+	cloned := *h
+	cloned.logger = h.logger.With(fields...)
+	cloned.groups = nil
+	return &cloned
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	cloned := *h
+	cloned.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &cloned
+}
+
+// fillGroups opens the pending groups as zap namespaces in the room left at the head of fields
+func (h *slogHandler) fillGroups(fields []zapcore.Field) {
+	for i := 0; i < len(h.groups) && i < len(fields); i++ {
+		fields[i] = zap.Namespace(h.groups[i])
+	}
+}
+
+// slogStack formats the stack from the frame of pc like zap does,
+// so the frames of slog and of the handler are skipped
+func slogStack(pc uintptr) string {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(2, pcs)
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, len(pcs)*2)
+	}
+	for i := range pcs {
+		if pcs[i] == pc {
+			pcs = pcs[i:]
+			break
+		}
+	}
+
+	buf := bufferpool.Get()
+	defer buf.Free()
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if buf.Len() > 0 {
+			buf.AppendByte('\n')
+		}
+		buf.AppendString(frame.Function)
+		buf.AppendString("\n\t")
+		buf.AppendString(frame.File)
+		buf.AppendByte(':')
+		buf.AppendInt(int64(frame.Line))
+		if !more {
+			break
+		}
+	}
+	return buf.String()
+}
+
+// appendSlogAttr appends the attr as a field, the empty attrs are ignored as slog requires
+func appendSlogAttr(fields []zapcore.Field, attr slog.Attr) []zapcore.Field {
+	if f := slogAttrToField(attr); f.Type != zapcore.SkipType {
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func slogAttrToField(attr slog.Attr) zapcore.Field {
+	if attr.Equal(slog.Attr{}) {
+		return zap.Skip()
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindBool:
+		return zap.Bool(attr.Key, attr.Value.Bool())
+	case slog.KindDuration:
+		return zap.Duration(attr.Key, attr.Value.Duration())
+	case slog.KindFloat64:
+		return zap.Float64(attr.Key, attr.Value.Float64())
+	case slog.KindInt64:
+		return zap.Int64(attr.Key, attr.Value.Int64())
+	case slog.KindString:
+		return zap.String(attr.Key, attr.Value.String())
+	case slog.KindTime:
+		return zap.Time(attr.Key, attr.Value.Time())
+	case slog.KindUint64:
+		return zap.Uint64(attr.Key, attr.Value.Uint64())
+	case slog.KindGroup:
+		var group slogGroup
+		for _, a := range attr.Value.Group() {
+			group = appendSlogAttr(group, a)
+		}
+		if len(group) == 0 {
+			// a group whose attrs are all empty is ignored too
+			return zap.Skip()
+		}
+		if attr.Key == "" {
+			// inline the attrs of a group without key as slog requires
+			return zap.Inline(group)
+		}
+		return zap.Object(attr.Key, group)
+	case slog.KindLogValuer:
+		return slogAttrToField(slog.Attr{Key: attr.Key, Value: attr.Value.Resolve()})
+	default:
+		return zap.Any(attr.Key, attr.Value.Any())
+	}
+}
+
+// slogGroup encodes the fields of a group as an object
+type slogGroup []zapcore.Field
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, f := range g {
+		f.AddTo(enc)
+	}
+	return nil
+}
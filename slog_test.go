@@ -0,0 +1,113 @@
+//go:build go1.21
+
+package lgr
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+type slogUser struct {
+	ID   int
+	Name string
+}
+
+func (u slogUser) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("id", u.ID), slog.String("name", u.Name))
+}
+
+func TestSlogHandler(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithName("app"), WithLevel("debug"), WithCustomSink(buf), WithTimeKey(""))
+	logger := log.With("foo", "bar").Slog()
+
+	logger.Debug("debug message", "uid", 7)
+	logger.Info("with user", "user", slogUser{ID: 7, Name: "user001"}, "elapsed", time.Second)
+	logger.WithGroup("req").With("method", "GET").WithGroup("empty").Warn("with group", "status", 404)
+	logger.WithGroup("unused").Error("group without attrs", slog.Group("", slog.String("inline", "yes")))
+	logger.Log(context.Background(), slog.LevelWarn+1, "custom level", slog.Group("empty"))
+
+	expect := `{"level":"debug","logger":"app","caller":"lgr/slog_test.go:28","msg":"debug message","foo":"bar","uid":7}
+{"level":"info","logger":"app","caller":"lgr/slog_test.go:29","msg":"with user","foo":"bar","user":{"id":7,"name":"user001"},"elapsed":1}
+{"level":"warn","logger":"app","caller":"lgr/slog_test.go:30","msg":"with group","foo":"bar","req":{"method":"GET","empty":{"status":404}}}
+{"level":"error","logger":"app","caller":"lgr/slog_test.go:31","msg":"group without attrs","foo":"bar","unused":{"inline":"yes"}}
+{"level":"warn","logger":"app","caller":"lgr/slog_test.go:32","msg":"custom level","foo":"bar"}
+`
+	if buf.String() != expect {
+		t.Errorf("unexpected output:\n%s\nexpect:\n%s", buf.String(), expect)
+	}
+}
+
+func TestSlogHandlerRespectsLevels(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithLevels("db=debug"), WithCustomSink(buf), WithTimeKey(""), WithDisableCaller(true))
+
+	log.Slog().Debug("root debug is filtered")
+	log.Named("db").Slog().Debug("db debug is visible")
+	if !log.Slog().Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("debug should be enabled for at least one logger name")
+	}
+
+	if got := strings.TrimSpace(buf.String()); got != `{"level":"debug","logger":"db","msg":"db debug is visible"}` {
+		t.Errorf("unexpected output %s", got)
+	}
+}
+
+func TestSlogHandlerZeroTime(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithDisableCaller(true))
+	h := log.SlogHandler().WithAttrs([]slog.Attr{slog.String("foo", "bar")})
+
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "without time", 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "with time", 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || lines[0] != `{"level":"info","msg":"without time","foo":"bar"}` {
+		t.Fatalf("expect the time left out, got %q", lines)
+	}
+	if !strings.Contains(lines[1], `"ts":`) || !strings.HasSuffix(lines[1], `"msg":"with time","foo":"bar"}`) {
+		t.Errorf("expect the time kept, got %q", lines[1])
+	}
+}
+
+func TestSlogHandlerEmptyGroup(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithTimeKey(""), WithDisableCaller(true))
+	logger := log.Slog().WithGroup("req")
+
+	logger.Info("empty attrs", slog.Attr{}, slog.Group("empty"), slog.Group("nested", slog.Group("empty")))
+	logger.With(slog.Attr{}).Info("empty with")
+	logger.Info("not empty", slog.Attr{}, "status", 200)
+
+	expect := `{"level":"info","msg":"empty attrs"}
+{"level":"info","msg":"empty with"}
+{"level":"info","msg":"not empty","req":{"status":200}}
+`
+	if buf.String() != expect {
+		t.Errorf("unexpected output:\n%s\nexpect:\n%s", buf.String(), expect)
+	}
+}
+
+func TestSlogHandlerLoggerOptions(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithTimeKey(""), WithDisableStacktrace(false))
+	log.Slog().Error("with stacktrace")
+	if !strings.Contains(buf.String(), `"stacktrace":"github.com/ttys3/lgr.TestSlogHandlerLoggerOptions\n\t`) {
+		t.Errorf("expect the stacktrace from the caller of slog, got %s", buf.String())
+	}
+
+	errBuf := bytes.NewBuffer([]byte(""))
+	log = NewLogger(WithCustomSink(failingWriter{}), WithCustomErrorSink(errBuf))
+	log.Slog().Info("write error")
+	if !strings.Contains(errBuf.String(), "disk full") {
+		t.Errorf("expect the write error in the error output, got %q", errBuf)
+	}
+}
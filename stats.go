@@ -0,0 +1,29 @@
+package lgr
+
+import "sync/atomic"
+
+// Stats holds the counters of a logger tree
+type Stats struct {
+	// SampledOut is the number of entries dropped by the sampler
+	SampledOut uint64
+	// AsyncDropped is the number of entries dropped because the async queue was full,
+	// or because Close timed out before they were written
+	AsyncDropped uint64
+	// MalformedPairs is the number of malformed key value pairs passed to the logging methods, see BadKeyPolicy
+	MalformedPairs uint64
+}
+
+type counters struct {
+	sampledOut     uint64
+	asyncDropped   uint64
+	malformedPairs uint64
+}
+
+// Stats returns the counters shared by all loggers derived from the same root via Named and With
+func (l *LogImpl) Stats() Stats {
+	return Stats{
+		SampledOut:     atomic.LoadUint64(&l.counters.sampledOut),
+		AsyncDropped:   atomic.LoadUint64(&l.counters.asyncDropped),
+		MalformedPairs: atomic.LoadUint64(&l.counters.malformedPairs),
+	}
+}
@@ -0,0 +1,482 @@
+package lgr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/ttys3/lgr/internal/bufferpool"
+)
+
+const (
+	// SyslogScheme is the url scheme of the syslog sink, e.g. syslog://logs.example.com:514 sends udp messages,
+	// syslog:// without a host uses the local syslog socket.
+	// udp://, tcp:// and unix:// urls are syslog sinks too, e.g. unix:///dev/log.
+	// the url parameters are format (rfc5424 or rfc3164), facility (e.g. local0) and sd_id.
+	SyslogScheme = "syslog"
+
+	defaultSyslogPort = "514"
+	// 32473 is the private enterprise number reserved for documentation, see RFC 5612
+	defaultSyslogSDID = "lgr@32473"
+)
+
+// the sockets of the local syslog daemon
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+const syslogDialTimeout = 5 * time.Second
+
+type syslogFormat int
+
+const (
+	syslogRFC5424 syslogFormat = iota
+	syslogRFC3164
+)
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslog severities
+const (
+	syslogEmerg = iota
+	syslogAlert
+	syslogCrit
+	syslogErr
+	syslogWarning
+	syslogNotice
+	syslogInfo
+	syslogDebug
+)
+
+// syslogSeverity maps the zap level to the syslog severity,
+// emerg is not used as it is broadcast to all terminals by most syslog daemons
+func syslogSeverity(lvl zapcore.Level) int {
+	switch {
+	case lvl >= zapcore.PanicLevel:
+		return syslogAlert
+	case lvl == zapcore.DPanicLevel:
+		return syslogCrit
+	case lvl == zapcore.ErrorLevel:
+		return syslogErr
+	case lvl == zapcore.WarnLevel:
+		return syslogWarning
+	case lvl == zapcore.InfoLevel:
+		return syslogInfo
+	default:
+		return syslogDebug
+	}
+}
+
+// isSyslogPath reports whether the output path is a syslog url
+func isSyslogPath(path string) bool {
+	u, err := url.Parse(path)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case SyslogScheme, "udp", "tcp", "unix":
+		return true
+	}
+	return false
+}
+
+// syslogConfig is parsed from a syslog url
+type syslogConfig struct {
+	network  string // empty for the local syslog socket
+	addr     string
+	format   syslogFormat
+	facility int
+	sdID     string
+}
+
+func parseSyslogURL(path string) (syslogConfig, error) {
+	sc := syslogConfig{facility: syslogFacilities["user"], sdID: defaultSyslogSDID}
+	u, err := url.Parse(path)
+	if err != nil {
+		return sc, err
+	}
+
+	switch u.Scheme {
+	case SyslogScheme, "udp", "tcp":
+		if u.Path != "" && u.Path != "/" {
+			return sc, fmt.Errorf("%s url %q must not have a path", u.Scheme, path)
+		}
+		if u.Host == "" {
+			if u.Scheme != SyslogScheme {
+				return sc, fmt.Errorf("missing host in %s url %q", u.Scheme, path)
+			}
+			// the local syslog socket, which usually expects the rfc3164 format
+			sc.format = syslogRFC3164
+			break
+		}
+		sc.network = u.Scheme
+		if u.Scheme == SyslogScheme {
+			sc.network = "udp"
+		}
+		sc.addr = u.Host
+		if u.Port() == "" {
+			sc.addr = net.JoinHostPort(u.Hostname(), defaultSyslogPort)
+		}
+	case "unix":
+		if u.Path == "" || u.Host != "" {
+			return sc, fmt.Errorf("unix url %q must have an absolute path, e.g. unix:///dev/log", path)
+		}
+		sc.network, sc.addr = "unix", u.Path
+		sc.format = syslogRFC3164
+	default:
+		return sc, fmt.Errorf("unknown syslog url scheme %q", u.Scheme)
+	}
+
+	q := u.Query()
+	switch format := q.Get("format"); format {
+	case "":
+	case "rfc5424":
+		sc.format = syslogRFC5424
+	case "rfc3164":
+		sc.format = syslogRFC3164
+	default:
+		return sc, fmt.Errorf("invalid format %q in syslog url, must be rfc5424 or rfc3164", format)
+	}
+	if facility := q.Get("facility"); facility != "" {
+		code, ok := syslogFacilities[facility]
+		if !ok {
+			return sc, fmt.Errorf("unknown facility %q in syslog url", facility)
+		}
+		sc.facility = code
+	}
+	if sdID := q.Get("sd_id"); sdID != "" {
+		sc.sdID = sdID
+	}
+	return sc, nil
+}
+
+var _ messageWriter = (*syslogWriter)(nil)
+
+// syslogWriter sends one syslog message per write, the connection is redialed once if a write fails,
+// then with a backoff if the dial fails too
+type syslogWriter struct {
+	cfg syslogConfig
+
+	mu     sync.Mutex
+	conn   net.Conn
+	stream bool
+	closed bool
+	redial redialer
+}
+
+func newSyslogWriter(path string) (*syslogWriter, error) {
+	cfg, err := parseSyslogURL(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &syslogWriter{cfg: cfg, redial: redialer{sink: "syslog"}}
+	if err := w.dial(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *syslogWriter) dial() error {
+	if w.cfg.network == "" {
+		for _, path := range localSyslogPaths {
+			if w.dialUnix(path) == nil {
+				return nil
+			}
+		}
+		return errors.New("no local syslog socket found")
+	}
+	if w.cfg.network == "unix" {
+		return w.dialUnix(w.cfg.addr)
+	}
+	conn, err := net.DialTimeout(w.cfg.network, w.cfg.addr, syslogDialTimeout)
+	if err != nil {
+		return err
+	}
+	w.conn, w.stream = conn, w.cfg.network == "tcp"
+	return nil
+}
+
+// dialUnix prefers datagrams, which is what most syslog daemons listen on
+func (w *syslogWriter) dialUnix(path string) error {
+	conn, err := net.DialTimeout("unixgram", path, syslogDialTimeout)
+	if err == nil {
+		w.conn, w.stream = conn, false
+		return nil
+	}
+	conn, err = net.DialTimeout("unix", path, syslogDialTimeout)
+	if err != nil {
+		return err
+	}
+	w.conn, w.stream = conn, true
+	return nil
+}
+
+func (w *syslogWriter) Write(msg []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, errors.New("syslog sink is closed")
+	}
+	if w.conn != nil {
+		if err := w.send(msg); err == nil {
+			return len(msg), nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	} else if w.redial.wait() {
+		// the failed dial has been reported, the message is only counted
+		return len(msg), nil
+	}
+	if err := w.dial(); err != nil {
+		w.redial.failed()
+		return 0, multierr.Append(err, w.redial.droppedErr())
+	}
+	w.redial.connected()
+	if err := w.send(msg); err != nil {
+		return 0, err
+	}
+	// the message is sent, the messages dropped while disconnected are still reported
+	return len(msg), w.redial.droppedErr()
+}
+
+// send frames the message for stream connections, see RFC 6587,
+// rfc3164 messages are terminated by a newline, so their own newlines, e.g. of a stacktrace, are escaped as #012 like rsyslog does
+func (w *syslogWriter) send(msg []byte) error {
+	if !w.stream {
+		_, err := w.conn.Write(msg)
+		return err
+	}
+	if w.cfg.format == syslogRFC3164 {
+		framed := bytes.ReplaceAll(msg, []byte("\n"), []byte("#012"))
+		_, err := w.conn.Write(append(framed, '\n'))
+		return err
+	}
+	_, err := w.conn.Write(append([]byte(strconv.Itoa(len(msg))+" "), msg...))
+	return err
+}
+
+// Sync does nothing, each message is sent by its write
+func (w *syslogWriter) Sync() error {
+	return nil
+}
+
+func (w *syslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+var _ zapcore.Core = (*syslogCore)(nil)
+
+// syslogCore frames each entry as a syslog message with the PRI of the entry level.
+// with rfc5424, the fields are sent as structured data and the message as MSG,
+// with rfc3164, which has no structured data, the entry is encoded with the output encoding as MSG.
+type syslogCore struct {
+	zapcore.LevelEnabler
+	w        *syslogWriter
+	enc      zapcore.Encoder
+	fields   []zapcore.Field
+	hostname string
+	appName  string
+	pid      int
+}
+
+func (w *syslogWriter) core(cfg *Config, enc zapcore.Encoder, enabler zapcore.LevelEnabler) zapcore.Core {
+	hostname, _ := os.Hostname()
+	return &syslogCore{
+		LevelEnabler: enabler,
+		w:            w,
+		enc:          enc,
+		hostname:     hostname,
+		appName:      cfg.appName(),
+		pid:          os.Getpid(),
+	}
+}
+
+// appName is the logger name, default to the program name
+func (cfg *Config) appName() string {
+	if cfg.Name != "" {
+		return cfg.Name
+	}
+	return filepath.Base(os.Args[0])
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(clone.enc)
+	}
+	clone.fields = make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	clone.fields = append(clone.fields, c.fields...)
+	clone.fields = append(clone.fields, fields...)
+	return &clone
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf := bufferpool.Get()
+	defer buf.Free()
+
+	pri := c.w.cfg.facility*8 + syslogSeverity(ent.Level)
+	if c.w.cfg.format == syslogRFC3164 {
+		msg, err := c.enc.EncodeEntry(ent, fields)
+		if err != nil {
+			return err
+		}
+		defer msg.Free()
+		fmt.Fprintf(buf, "<%d>%s ", pri, ent.Time.Format(time.Stamp))
+		// the local syslog daemon adds the hostname itself
+		if c.w.cfg.network != "" && c.w.cfg.network != "unix" {
+			buf.AppendString(c.hostname)
+			buf.AppendByte(' ')
+		}
+		fmt.Fprintf(buf, "%s[%d]: ", c.appName, c.pid)
+		buf.Write(trimNewline(msg.Bytes()))
+	} else {
+		fmt.Fprintf(buf, "<%d>1 %s %s %s %d - ",
+			pri, ent.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+			syslogHeaderField(c.hostname, 255), syslogHeaderField(c.appName, 48), c.pid)
+		c.appendStructuredData(buf, ent, fields)
+		buf.AppendByte(' ')
+		buf.AppendString(ent.Message)
+		if ent.Stack != "" {
+			buf.AppendByte('\n')
+			buf.AppendString(ent.Stack)
+		}
+	}
+
+	_, err := c.w.Write(buf.Bytes())
+	return err
+}
+
+// appendStructuredData appends the logger name, the caller and the fields as one SD-ELEMENT,
+// the params are sorted by name
+func (c *syslogCore) appendStructuredData(buf *buffer.Buffer, ent zapcore.Entry, fields []zapcore.Field) {
+	m := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(m)
+	}
+	for _, f := range fields {
+		f.AddTo(m)
+	}
+	if ent.LoggerName != "" {
+		m.Fields["logger"] = ent.LoggerName
+	}
+	if ent.Caller.Defined {
+		m.Fields["caller"] = ent.Caller.TrimmedPath()
+	}
+	if len(m.Fields) == 0 {
+		buf.AppendByte('-')
+		return
+	}
+
+	names := make([]string, 0, len(m.Fields))
+	for name := range m.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf.AppendByte('[')
+	buf.AppendString(c.w.cfg.sdID)
+	for _, name := range names {
+		buf.AppendByte(' ')
+		buf.AppendString(sdParamName(name))
+		buf.AppendString(`="`)
+		buf.AppendString(sdParamEscaper.Replace(fieldString(m.Fields[name])))
+		buf.AppendByte('"')
+	}
+	buf.AppendByte(']')
+}
+
+func (c *syslogCore) Sync() error {
+	return c.w.Sync()
+}
+
+func trimNewline(b []byte) []byte {
+	for len(b) > 0 && b[len(b)-1] == '\n' {
+		b = b[:len(b)-1]
+	}
+	return b
+}
+
+// syslogHeaderField returns s as a printable ascii header field of at most max chars, or "-" if empty
+func syslogHeaderField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "-"
+	}
+	if len(s) > max {
+		s = s[:max]
+	}
+	return s
+}
+
+// sdParamName replaces the chars not allowed in a PARAM-NAME, which has at most 32 chars
+func sdParamName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+var sdParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// fieldString formats a value of a zapcore.MapObjectEncoder, objects and arrays as json
+func fieldString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,279 @@
+package lgr
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseSyslogURL(t *testing.T) {
+	tests := []struct {
+		path   string
+		expect syslogConfig
+		err    string
+	}{
+		{path: "syslog://logs.example.com", expect: syslogConfig{network: "udp", addr: "logs.example.com:514", facility: 1, sdID: defaultSyslogSDID}},
+		{path: "syslog://", expect: syslogConfig{format: syslogRFC3164, facility: 1, sdID: defaultSyslogSDID}},
+		{path: "udp://127.0.0.1:5140?facility=local0", expect: syslogConfig{network: "udp", addr: "127.0.0.1:5140", facility: 16, sdID: defaultSyslogSDID}},
+		{path: "tcp://logs.example.com?format=rfc3164", expect: syslogConfig{network: "tcp", addr: "logs.example.com:514", format: syslogRFC3164, facility: 1, sdID: defaultSyslogSDID}},
+		{path: "unix:///dev/log?sd_id=app@1234", expect: syslogConfig{network: "unix", addr: "/dev/log", format: syslogRFC3164, facility: 1, sdID: "app@1234"}},
+		{path: "udp://", err: "missing host"},
+		{path: "tcp://logs.example.com/app", err: "must not have a path"},
+		{path: "unix://dev/log", err: "must have an absolute path"},
+		{path: "syslog://logs.example.com?format=json", err: "invalid format"},
+		{path: "syslog://logs.example.com?facility=local9", err: "unknown facility"},
+	}
+	for _, tt := range tests {
+		sc, err := parseSyslogURL(tt.path)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: expect error containing %q, got %v", tt.path, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.path, err)
+			continue
+		}
+		if sc != tt.expect {
+			t.Errorf("%s: expect %+v, got %+v", tt.path, tt.expect, sc)
+		}
+	}
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	for lvl, expect := range map[zapcore.Level]int{
+		TraceLevel:          7,
+		zapcore.DebugLevel:  7,
+		zapcore.InfoLevel:   6,
+		zapcore.WarnLevel:   4,
+		zapcore.ErrorLevel:  3,
+		zapcore.DPanicLevel: 2,
+		zapcore.PanicLevel:  1,
+		zapcore.FatalLevel:  1,
+	} {
+		if got := syslogSeverity(lvl); got != expect {
+			t.Errorf("%v: expect %d, got %d", lvl, expect, got)
+		}
+	}
+}
+
+// readPackets returns the first n packets received by conn
+func readPackets(t *testing.T, conn net.PacketConn, n int) []string {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	packets := make([]string, 0, n)
+	buf := make([]byte, 64*1024)
+	for len(packets) < n {
+		size, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, string(buf[:size]))
+	}
+	return packets
+}
+
+func TestSyslogRFC5424OverUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	log := NewLogger(WithName("myapp"), WithDisableCaller(true), WithLevel("debug"),
+		WithOutputPaths("syslog://"+conn.LocalAddr().String()+"?facility=local0"))
+	log.With("user_id", 42).Info("user login", "note", `say "hi"]`)
+	log.Named("db").Debug("query")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	hostname, _ := os.Hostname()
+	header := fmt.Sprintf(`^<%%d>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}\S+ %s myapp %d - `, regexp.QuoteMeta(hostname), os.Getpid())
+	packets := readPackets(t, conn, 2)
+	for i, expect := range []string{
+		fmt.Sprintf(header, 16*8+6) + regexp.QuoteMeta(`[lgr@32473 logger="myapp" note="say \"hi\"\]" user_id="42"] user login`) + `$`,
+		fmt.Sprintf(header, 16*8+7) + regexp.QuoteMeta(`[lgr@32473 logger="myapp.db"] query`) + `$`,
+	} {
+		if !regexp.MustCompile(expect).MatchString(packets[i]) {
+			t.Errorf("expect %s, got %q", expect, packets[i])
+		}
+	}
+}
+
+func TestSyslogRFC3164OverUnix(t *testing.T) {
+	dir, err := os.MkdirTemp("", "lgr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// unix socket paths are limited to about 100 chars, so t.TempDir may be too long
+	path := filepath.Join(dir, "log.sock")
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	log := NewLogger(WithName("myapp"), WithDisableCaller(true), WithTimeKey(""),
+		WithOutputs(OutputConfig{Paths: []string{"unix://" + path}, Level: "warn"}))
+	log.Info("filtered")
+	log.Error("disk full", "free", 0)
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := fmt.Sprintf(`^<11>\w{3} [ \d]\d \d\d:\d\d:\d\d myapp\[%d\]: `, os.Getpid()) +
+		regexp.QuoteMeta(`{"level":"error","logger":"myapp","msg":"disk full","free":0}`) + `$`
+	if packet := readPackets(t, conn, 1)[0]; !regexp.MustCompile(expect).MatchString(packet) {
+		t.Errorf("expect %s, got %q", expect, packet)
+	}
+}
+
+func TestSyslogOverTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var size int
+		if _, err := fmt.Fscanf(r, "%d ", &size); err != nil {
+			received <- err.Error()
+			return
+		}
+		msg := make([]byte, size)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			received <- err.Error()
+			return
+		}
+		received <- string(msg)
+	}()
+
+	log := NewLogger(WithName("myapp"), WithDisableCaller(true), WithOutputPaths("tcp://"+ln.Addr().String()))
+	log.Warn("slow request")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-received:
+		if !strings.HasPrefix(msg, "<12>1 ") || !strings.HasSuffix(msg, ` - [lgr@32473 logger="myapp"] slow request`) {
+			t.Errorf("unexpected message %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the message")
+	}
+}
+
+func TestSyslogDialError(t *testing.T) {
+	_, err := NewLoggerE(WithOutputPaths("unix:///nonexistent/log.sock"))
+	if err == nil || !strings.Contains(err.Error(), "open syslog") {
+		t.Errorf("expect open syslog error, got %v", err)
+	}
+}
+
+func TestSyslogRedialBackoff(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	w := &syslogWriter{cfg: syslogConfig{network: "tcp", addr: addr}, redial: redialer{sink: "syslog"}}
+	if _, err := w.Write([]byte("first")); err == nil {
+		t.Fatal("expect the dial error")
+	}
+	// the dropped messages are counted, not reported one by one
+	for _, msg := range []string{"second", "third"} {
+		if n, err := w.Write([]byte(msg)); err != nil || n != len(msg) {
+			t.Fatalf("expect the message to be dropped silently until the next redial, got %d, %v", n, err)
+		}
+	}
+	if w.redial.interval != minRedialInterval {
+		t.Errorf("expect redial interval %v, got %v", minRedialInterval, w.redial.interval)
+	}
+
+	ln, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	w.cfg.addr = ln.Addr().String()
+	w.redial.at = time.Now()
+	if _, err := w.Write([]byte("fourth")); err == nil || err.Error() != "syslog sink dropped 2 messages while disconnected" {
+		t.Fatalf("expect the dropped messages reported, got %v", err)
+	}
+	if _, err := w.Write([]byte("fifth")); err != nil {
+		t.Fatal(err)
+	}
+	if w.redial.interval != 0 {
+		t.Errorf("expect the redial interval to be reset, got %v", w.redial.interval)
+	}
+	w.Close()
+}
+
+func TestSyslogRFC3164OverTCPEscapesNewlines(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for i := 0; i < 2; i++ {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				received <- err.Error()
+				return
+			}
+			received <- line
+		}
+	}()
+
+	log := NewLogger(WithName("myapp"), WithDisableCaller(true), WithTimeKey(""), WithEncoding("console"), WithColorLevel(false),
+		WithOutputPaths("tcp://"+ln.Addr().String()+"?format=rfc3164"))
+	log.Warn("first line\nsecond line")
+	log.Info("next message")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expect := range []string{"myapp\tfirst line#012second line\n", "myapp\tnext message\n"} {
+		select {
+		case msg := <-received:
+			if !strings.HasSuffix(msg, expect) {
+				t.Errorf("expect a message ending with %q, got %q", expect, msg)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the message")
+		}
+	}
+}
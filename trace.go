@@ -0,0 +1,91 @@
+package lgr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceKeys names the trace correlation fields added by the *Ctx methods
+// when the context carries an OpenTelemetry span, an empty name omits the field
+type TraceKeys struct {
+	TraceID    string `json:"trace_id" yaml:"trace_id"`
+	SpanID     string `json:"span_id" yaml:"span_id"`
+	TraceFlags string `json:"trace_flags" yaml:"trace_flags"`
+}
+
+var (
+	// OTelTraceKeys follows the OpenTelemetry naming, it is the default
+	OTelTraceKeys = TraceKeys{TraceID: "trace_id", SpanID: "span_id", TraceFlags: "trace_flags"}
+	// ECSTraceKeys follows the Elastic Common Schema naming, which has no trace flags field
+	ECSTraceKeys = TraceKeys{TraceID: "trace.id", SpanID: "span.id"}
+)
+
+// traceKeysAndValues returns the trace correlation pairs of the span carried by ctx
+func (l *LogImpl) traceKeysAndValues(ctx context.Context) []interface{} {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+
+	keys := l.TraceKeys
+	kv := make([]interface{}, 0, 6)
+	if keys.TraceID != "" {
+		kv = append(kv, keys.TraceID, sc.TraceID().String())
+	}
+	if keys.SpanID != "" {
+		kv = append(kv, keys.SpanID, sc.SpanID().String())
+	}
+	if keys.TraceFlags != "" {
+		kv = append(kv, keys.TraceFlags, sc.TraceFlags().String())
+	}
+	return kv
+}
+
+// addSpanEvent mirrors the entry as an event of the recording span carried by ctx
+func (l *LogImpl) addSpanEvent(ctx context.Context, level, msg string, keysAndValues []interface{}) {
+	if !l.SpanEvents || ctx == nil {
+		return
+	}
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(keysAndValues)/2+1)
+	attrs = append(attrs, attribute.String("log.severity", level))
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			continue
+		}
+		attrs = append(attrs, toAttribute(key, keysAndValues[i+1]))
+	}
+	span.AddEvent(msg, trace.WithAttributes(attrs...))
+}
+
+func toAttribute(key string, val interface{}) attribute.KeyValue {
+	switch v := val.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case time.Duration:
+		return attribute.String(key, v.String())
+	case error:
+		return attribute.String(key, v.Error())
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,108 @@
+package lgr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTraceCorrelation(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	tests := []struct {
+		keys   TraceKeys
+		expect []string
+	}{
+		{OTelTraceKeys, []string{"trace_id", "span_id", "trace_flags"}},
+		{ECSTraceKeys, []string{"trace.id", "span.id"}},
+		{TraceKeys{}, nil},
+	}
+	for _, tt := range tests {
+		buf := bytes.NewBuffer([]byte(""))
+		log := NewLogger(WithCustomSink(buf), WithTimeKey(""), WithDisableCaller(true), WithTraceKeys(tt.keys))
+
+		ctx, span := tp.Tracer("lgr").Start(context.Background(), "operation")
+		log.InfoCtx(ctx, "info message", "uid", 7)
+		span.End()
+
+		var entry map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if len(entry) != 3+len(tt.expect) {
+			t.Errorf("unexpected fields %v, expect trace fields %v", entry, tt.expect)
+		}
+		values := []string{span.SpanContext().TraceID().String(), span.SpanContext().SpanID().String(), "01"}
+		for i, key := range tt.expect {
+			if entry[key] != values[i] {
+				t.Errorf("expect %s=%s, got %v", key, values[i], entry[key])
+			}
+		}
+	}
+}
+
+func TestNoTraceFieldsWithoutSpan(t *testing.T) {
+	buf := bytes.NewBuffer([]byte(""))
+	log := NewLogger(WithCustomSink(buf), WithTimeKey(""), WithDisableCaller(true))
+	log.InfoCtx(context.Background(), "info message")
+	if got := strings.TrimSpace(buf.String()); got != `{"level":"info","msg":"info message"}` {
+		t.Errorf("unexpected output %s", got)
+	}
+}
+
+func TestSpanEvents(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	log := NewLogger(WithCustomSink(bytes.NewBuffer([]byte(""))), WithSpanEvents(true))
+	ctx, span := tp.Tracer("lgr").Start(context.Background(), "operation")
+	log.InfoCtx(ctx, "info is not mirrored")
+	log.ErrorCtx(ctx, "something bad happened", "uid", 7, "name", "user001")
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || len(spans[0].Events) != 1 {
+		t.Fatalf("expect one span with one event, got %+v", spans)
+	}
+	event := spans[0].Events[0]
+	if event.Name != "something bad happened" {
+		t.Errorf("unexpected event name %s", event.Name)
+	}
+	expect := []attribute.KeyValue{
+		attribute.String("log.severity", "error"),
+		attribute.Int("uid", 7),
+		attribute.String("name", "user001"),
+	}
+	if len(event.Attributes) != len(expect) {
+		t.Fatalf("unexpected attributes %v", event.Attributes)
+	}
+	for i := range expect {
+		if event.Attributes[i] != expect[i] {
+			t.Errorf("expect attribute %v, got %v", expect[i], event.Attributes[i])
+		}
+	}
+}
+
+func TestSpanEventsRespectLevel(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	log := NewLogger(WithCustomSink(bytes.NewBuffer(nil)), WithSpanEvents(true), WithLevel("fatal"))
+	ctx, span := tp.Tracer("lgr").Start(context.Background(), "operation")
+	log.ErrorCtx(ctx, "filtered")
+	span.End()
+
+	if spans := exporter.GetSpans(); len(spans) != 1 || len(spans[0].Events) != 0 {
+		t.Errorf("expect no event for a filtered entry, got %+v", spans)
+	}
+}
@@ -1,23 +1,110 @@
 package lgr
 
 import (
+	"fmt"
+	"strings"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// TraceLevel logs are more verbose than debug logs, e.g. protocol dumps
+const TraceLevel = zapcore.DebugLevel - 1
+
+// ParseLevel parses a level name case-insensitively, common aliases like "warning" and "err" are accepted.
+// an error is returned for an unknown level name.
+func ParseLevel(text string) (zapcore.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "trace":
+		return TraceLevel, nil
+	case "debug", "dbg":
+		return zap.DebugLevel, nil
+	case "info", "information":
+		return zap.InfoLevel, nil
+	case "warn", "warning":
+		return zap.WarnLevel, nil
+	case "error", "err":
+		return zap.ErrorLevel, nil
+	case "dpanic":
+		return zap.DPanicLevel, nil
+	case "panic":
+		return zap.PanicLevel, nil
+	case "fatal", "critical":
+		return zap.FatalLevel, nil
+	}
+	return zap.InfoLevel, fmt.Errorf("unrecognized level: %q", text)
+}
+
+// getZapLevel parses level like ParseLevel, but falls back to info for an unknown level name
 func getZapLevel(level string) zapcore.Level {
-	zapLevel := zap.InfoLevel
-	switch level {
-	case "debug":
-		zapLevel = zap.DebugLevel
-	case "info":
-		zapLevel = zap.InfoLevel
-	case "warn":
-		zapLevel = zap.WarnLevel
-	case "error":
-		zapLevel = zap.ErrorLevel
-	case "fatal":
-		zapLevel = zap.FatalLevel
+	zapLevel, err := ParseLevel(level)
+	if err != nil {
+		return zap.InfoLevel
 	}
 	return zapLevel
 }
+
+// levelName returns the lowercase name of the level, zap has no name for TraceLevel
+func levelName(lvl zapcore.Level) string {
+	if lvl == TraceLevel {
+		return "trace"
+	}
+	return lvl.String()
+}
+
+// LowercaseLevelEncoder is zapcore.LowercaseLevelEncoder which knows TraceLevel
+func LowercaseLevelEncoder(lvl zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(levelName(lvl))
+}
+
+// CapitalColorLevelEncoder is zapcore.CapitalColorLevelEncoder which knows TraceLevel
+func CapitalColorLevelEncoder(lvl zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	if lvl == TraceLevel {
+		// cyan, zap uses magenta for debug
+		enc.AppendString("\x1b[36mTRACE\x1b[0m")
+		return
+	}
+	zapcore.CapitalColorLevelEncoder(lvl, enc)
+}
+
+// checkKeysAndValues reports the first pair which sweetenFields would ignore
+func checkKeysAndValues(keysAndValues []interface{}) error {
+	for i := 0; i < len(keysAndValues); {
+		if _, ok := keysAndValues[i].(zap.Field); ok {
+			i++
+			continue
+		}
+		if _, ok := keysAndValues[i].(string); !ok {
+			return fmt.Errorf("key at index %d must be a string, got %T", i, keysAndValues[i])
+		}
+		if i == len(keysAndValues)-1 {
+			return fmt.Errorf("key %q has no value", keysAndValues[i])
+		}
+		i += 2
+	}
+	return nil
+}
+
+// sweetenFields converts loosely typed key value pairs to fields like zap.SugaredLogger does,
+// a zap.Field is used as is, pairs with a non-string key and a dangling key are ignored.
+func sweetenFields(keysAndValues []interface{}) []zap.Field {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+	fields := make([]zap.Field, 0, len(keysAndValues))
+	for i := 0; i < len(keysAndValues); {
+		if f, ok := keysAndValues[i].(zap.Field); ok {
+			fields = append(fields, f)
+			i++
+			continue
+		}
+		if i == len(keysAndValues)-1 {
+			break
+		}
+		if key, ok := keysAndValues[i].(string); ok {
+			fields = append(fields, zap.Any(key, keysAndValues[i+1]))
+		}
+		i += 2
+	}
+	return fields
+}